@@ -0,0 +1,298 @@
+package pageviewer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/input"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+const (
+	actionResultMaxLength   = 1024
+	scrollGrowthWaitTimeout = 2 * time.Second
+)
+
+// Action 导航完成后、抽取之前在页面上执行的交互动作，通过 ActionClick 等构造函数创建
+type Action struct {
+	name     string
+	optional bool
+	do       func(page *rod.Page) (string, error)
+}
+
+// ActionResult 单个动作的执行结果，记录在 TraceAttempt.Actions 中
+type ActionResult struct {
+	Name     string
+	Duration time.Duration
+	Result   string // 动作的返回值，例如 ActionEval 的 JSON 结果、ActionScrollToBottom 实际滚动的次数
+	Error    string
+	Optional bool
+}
+
+func (a Action) String() string {
+	return a.name
+}
+
+// Optional 返回失败时只记录错误、继续执行后续动作的副本，适合处理不一定出现的弹层或同意按钮
+func (a Action) Optional() Action {
+	a.optional = true
+	return a
+}
+
+// ActionClick 点击匹配 CSS 选择器的第一个元素，点击前会滚动到元素并等待元素可交互
+func ActionClick(selector string) Action {
+	return Action{
+		name: fmt.Sprintf("click(%q)", selector),
+		do: func(page *rod.Page) (string, error) {
+			el, err := page.Element(selector)
+			if err != nil {
+				return "", err
+			}
+			return "", el.Click(proto.InputMouseButtonLeft, 1)
+		},
+	}
+}
+
+// ActionType 聚焦匹配 CSS 选择器的输入框并输入 text
+func ActionType(selector, text string) Action {
+	return Action{
+		name: fmt.Sprintf("type(%q)", selector),
+		do: func(page *rod.Page) (string, error) {
+			el, err := page.Element(selector)
+			if err != nil {
+				return "", err
+			}
+			return "", el.Input(text)
+		},
+	}
+}
+
+// ActionPress 在当前焦点上依次按下并释放按键，例如 ActionPress(input.Enter)
+func ActionPress(keys ...input.Key) Action {
+	names := make([]string, 0, len(keys))
+	for _, key := range keys {
+		names = append(names, key.Info().Code)
+	}
+	return Action{
+		name: "press(" + strings.Join(names, ", ") + ")",
+		do: func(page *rod.Page) (string, error) {
+			// page.Keyboard 绑定的是创建时的页面，不受本次动作的 context 约束，这里直接在 page 上分发按键事件
+			for _, key := range keys {
+				if err := key.Encode(proto.InputDispatchKeyEventTypeKeyDown, 0).Call(page); err != nil {
+					return "", err
+				}
+				if err := key.Encode(proto.InputDispatchKeyEventTypeKeyUp, 0).Call(page); err != nil {
+					return "", err
+				}
+			}
+			return "", nil
+		},
+	}
+}
+
+// ActionSelect 在 select 元素中选中 value 或文本匹配 values 的选项，并触发 input / change 事件
+func ActionSelect(selector string, values ...string) Action {
+	return Action{
+		name: fmt.Sprintf("select(%q, %q)", selector, values),
+		do: func(page *rod.Page) (string, error) {
+			el, err := page.Element(selector)
+			if err != nil {
+				return "", err
+			}
+			r, err := el.Eval(`function (values) {
+				if (!this.options) {
+					throw new Error('element is not a select');
+				}
+				let matched = 0;
+				for (const option of this.options) {
+					option.selected = values.includes(option.value) || values.includes(option.text.trim());
+					if (option.selected) {
+						matched++;
+					}
+				}
+				if (matched === 0) {
+					throw new Error('no option matches ' + values.join(', '));
+				}
+				this.dispatchEvent(new Event('input', { bubbles: true }));
+				this.dispatchEvent(new Event('change', { bubbles: true }));
+				return matched;
+			}`, values)
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("%d selected", r.Value.Int()), nil
+		},
+	}
+}
+
+// ActionScrollToBottom 滚动到页面底部最多 times 次，每次滚动后等待页面高度增长，
+// 高度不再变化时提前结束，适合加载无限滚动的信息流
+func ActionScrollToBottom(times int) Action {
+	return Action{
+		name: fmt.Sprintf("scroll-to-bottom(%d)", times),
+		do: func(page *rod.Page) (string, error) {
+			scrolled := 0
+			for scrolled < times {
+				grown, err := scrollToBottom(page, 0, scrollGrowthWaitTimeout)
+				if err != nil {
+					return "", err
+				}
+				scrolled++
+				if !grown {
+					break
+				}
+			}
+			return fmt.Sprintf("%d scrolled", scrolled), nil
+		},
+	}
+}
+
+// ActionHover 把鼠标移动到匹配 CSS 选择器的元素上，用于展开悬停菜单
+func ActionHover(selector string) Action {
+	return Action{
+		name: fmt.Sprintf("hover(%q)", selector),
+		do: func(page *rod.Page) (string, error) {
+			el, err := page.Element(selector)
+			if err != nil {
+				return "", err
+			}
+			return "", el.Hover()
+		},
+	}
+}
+
+// ActionWait 依次等待全部条件满足，通常放在点击或提交之后等待新内容出现
+func ActionWait(conditions ...WaitCondition) Action {
+	condition := WaitAll(conditions...)
+	return Action{
+		name: "wait(" + strings.TrimSuffix(strings.TrimPrefix(condition.String(), "all("), ")") + ")",
+		do: func(page *rod.Page) (string, error) {
+			return "", condition.run(page)
+		},
+	}
+}
+
+// ActionSubmit 提交匹配 CSS 选择器的表单，selector 也可以指向表单内的元素；
+// 提交引起的页面跳转不会自动等待，需要时在后面追加 ActionWait
+func ActionSubmit(selector string) Action {
+	return Action{
+		name: fmt.Sprintf("submit(%q)", selector),
+		do: func(page *rod.Page) (string, error) {
+			el, err := page.Element(selector)
+			if err != nil {
+				return "", err
+			}
+			_, err = el.Eval(`function () {
+				const form = this instanceof HTMLFormElement ? this : (this.form || this.closest('form'));
+				if (!form) {
+					throw new Error('no form found');
+				}
+				if (form.requestSubmit) {
+					form.requestSubmit();
+				} else {
+					form.submit();
+				}
+			}`)
+			return "", err
+		},
+	}
+}
+
+// ActionEval 执行 JS 并把返回值的 JSON 记录到 ActionResult.Result，js 可以是表达式，也可以是无参函数
+func ActionEval(js string) Action {
+	return Action{
+		name: fmt.Sprintf("eval(%q)", js),
+		do: func(page *rod.Page) (string, error) {
+			r, err := page.Evaluate(rod.Eval(jsFunction(js)).ByPromise())
+			if err != nil {
+				return "", err
+			}
+			return r.Value.JSON("", ""), nil
+		},
+	}
+}
+
+// scrollToBottom 滚动到底部，先停顿 stepDelay，再最多等待 growthTimeout 观察页面高度是否增长，返回高度是否增长
+func scrollToBottom(page *rod.Page, stepDelay, growthTimeout time.Duration) (bool, error) {
+	r, err := page.Eval(`() => {
+		const root = document.scrollingElement || document.documentElement;
+		const height = root.scrollHeight;
+		window.scrollTo(0, height);
+		return height;
+	}`)
+	if err != nil {
+		return false, err
+	}
+	if stepDelay > 0 {
+		timer := time.NewTimer(stepDelay)
+		select {
+		case <-page.GetContext().Done():
+			timer.Stop()
+			return false, page.GetContext().Err()
+		case <-timer.C:
+		}
+	}
+
+	err = page.Timeout(growthTimeout).Wait(rod.Eval(`(height) => (document.scrollingElement || document.documentElement).scrollHeight > height`, r.Value.Int()))
+	switch {
+	case err == nil:
+		return true, nil
+	case page.GetContext().Err() != nil:
+		return false, page.GetContext().Err()
+	case errors.Is(err, context.DeadlineExceeded):
+		return false, nil
+	default:
+		return false, err
+	}
+}
+
+// runActions 依次执行动作，每个动作最多 timeout，结果交给 record；
+// 非 Optional 动作失败时停止并返回错误，调用方 ctx 取消时返回 ctx.Err()
+func runActions(ctx context.Context, page *rod.Page, timeout time.Duration, actions []Action, record func(ActionResult)) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if timeout <= 0 {
+		timeout = DefaultWaitStableTimeout
+	}
+
+	for _, action := range actions {
+		result, err := runAction(ctx, page, timeout, action)
+		if record != nil {
+			record(result)
+		}
+		if err == nil || action.optional && ctx.Err() == nil {
+			continue
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("pageviewer: action %s: %w", action, err)
+	}
+	return nil
+}
+
+func runAction(ctx context.Context, page *rod.Page, timeout time.Duration, action Action) (ActionResult, error) {
+	actionCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	result := ActionResult{Name: action.name, Optional: action.optional}
+
+	var err error
+	if action.do != nil {
+		result.Result, err = action.do(page.Context(actionCtx))
+	}
+	result.Duration = time.Since(start)
+	if len(result.Result) > actionResultMaxLength {
+		result.Result = strings.ToValidUTF8(result.Result[:actionResultMaxLength], "")
+	}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	return result, err
+}
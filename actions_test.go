@@ -0,0 +1,92 @@
+package pageviewer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-rod/rod/lib/input"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestActionNames(t *testing.T) {
+	assert.Equal(t, `click("#more")`, ActionClick("#more").String())
+	assert.Equal(t, `type("#q")`, ActionType("#q", "secret").String())
+	assert.Equal(t, "press(Enter, Tab)", ActionPress(input.Enter, input.Tab).String())
+	assert.Equal(t, `select("#sort", ["new" "hot"])`, ActionSelect("#sort", "new", "hot").String())
+	assert.Equal(t, "scroll-to-bottom(3)", ActionScrollToBottom(3).String())
+	assert.Equal(t, `wait(visible("#list"), delay(0s))`, ActionWait(WaitVisible("#list"), WaitDelay(0)).String())
+	assert.Equal(t, `eval("1 + 1")`, ActionEval("1 + 1").String())
+
+	action := ActionClick("#consent")
+	assert.True(t, action.Optional().optional)
+	assert.False(t, action.optional)
+}
+
+func TestClientRunsActionsBeforeExtraction(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(`<html><head><title>actions</title></head><body>
+<ul id="list"><li>item 1</li></ul>
+<button id="more" onclick="const li = document.createElement('li'); li.textContent = 'item ' + (document.querySelectorAll('#list li').length + 1); document.getElementById('list').appendChild(li);">more</button>
+<form id="search" onsubmit="event.preventDefault(); document.getElementById('out').textContent = 'query=' + this.q.value + ' sort=' + this.sort.value;">
+<input id="q" name="q"><select id="sort" name="sort"><option value="hot">Hot</option><option value="new">Newest</option></select>
+</form>
+<p id="out"></p>
+</body></html>`))
+	}))
+	defer s.Close()
+
+	client := newTestClient(t, Config{PoolSize: 1, Warmup: 1})
+
+	html, err := client.HTML(context.Background(), s.URL,
+		WithTraceID("actions-ok"),
+		WithActions(
+			ActionClick("#consent").Optional(),
+			ActionClick("#more"),
+			ActionClick("#more"),
+			ActionType("#q", "golang"),
+			ActionSelect("#sort", "Newest"),
+			ActionSubmit("#q"),
+			ActionWait(WaitText("query=golang")),
+			ActionEval(`document.querySelectorAll('#list li').length`),
+		),
+		WithWaitTimeout(2*time.Second),
+	)
+	require.NoError(t, err)
+	assert.Contains(t, html, "item 3")
+	assert.Contains(t, html, "query=golang sort=new")
+
+	trace, ok := client.DebugTrace("actions-ok")
+	require.True(t, ok)
+	require.Len(t, trace.Actions, 8)
+	assert.True(t, trace.Actions[0].Optional)
+	assert.NotEmpty(t, trace.Actions[0].Error)
+	assert.Equal(t, "1 selected", trace.Actions[4].Result)
+	assert.Equal(t, "3", trace.Actions[7].Result)
+
+	_, err = client.HTML(context.Background(), s.URL, WithTraceID("actions-fail"), WithWaitTimeout(2*time.Second), WithActions(ActionClick("#missing"), ActionEval("1")))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `action click("#missing")`)
+
+	trace, ok = client.DebugTrace("actions-fail")
+	require.True(t, ok)
+	assert.Len(t, trace.Actions, 1)
+}
+
+func TestActionPressStopsWithActionContext(t *testing.T) {
+	page, err := sharedTestBrowser(t).GetPage()
+	require.NoError(t, err)
+	defer func() {
+		_ = page.Close()
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = runAction(ctx, page, time.Second, ActionPress(input.Enter))
+	assert.ErrorIs(t, err, context.Canceled)
+}
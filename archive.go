@@ -0,0 +1,308 @@
+package pageviewer
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+type ArchiveFormat string
+
+const (
+	ArchiveFormatMHTML      ArchiveFormat = "mhtml" // 浏览器原生 MHTML 快照
+	ArchiveFormatSingleFile ArchiveFormat = "html"  // 图片、样式、字体内联为 data URI 的单文件 HTML
+)
+
+// archiveMaxCSSDepth 限制 @import 链的递归深度
+const archiveMaxCSSDepth = 4
+
+var (
+	cssURLPattern = regexp.MustCompile(`url\(\s*(['"]?)([^'")]+)(['"]?)\s*\)`)
+	// cssImportStringPattern 匹配 @import "x.css" 形式的字符串导入，改写成 url() 形式后统一内联
+	cssImportStringPattern = regexp.MustCompile(`@import\s+(['"])([^'"]+)['"]`)
+)
+
+// ArchiveOptions 离线归档配置项
+type ArchiveOptions struct {
+	Format ArchiveFormat // 归档格式，默认 mhtml
+}
+
+// Archive 把渲染后的页面保存为可离线查看的自包含归档
+func (c *Client) Archive(ctx context.Context, url string, ao ArchiveOptions, opts ...RequestOption) ([]byte, error) {
+	switch ao.Format {
+	case "":
+		ao.Format = ArchiveFormatMHTML
+	case ArchiveFormatMHTML, ArchiveFormatSingleFile:
+	default:
+		return nil, fmt.Errorf("pageviewer: unsupported archive format: %s", ao.Format)
+	}
+
+	var data []byte
+	err := c.visitWithOptions(ctx, url, NewRequestOptions(opts...), true, func(page *rod.Page, _ *proto.NetworkResponseReceived) error {
+		if ao.Format == ArchiveFormatSingleFile {
+			html, err := singleFileHTML(page)
+			data = []byte(html)
+			return err
+		}
+
+		snapshot, err := proto.PageCaptureSnapshot{Format: proto.PageCaptureSnapshotFormatMhtml}.Call(page)
+		if err != nil {
+			return err
+		}
+		data = []byte(snapshot.Data)
+		return nil
+	})
+	return data, err
+}
+
+type archiveCollected struct {
+	Resources   []string `json:"resources"`
+	Stylesheets []string `json:"stylesheets"`
+}
+
+// archiveInliner 从浏览器缓存中读取子资源并转换为 data URI，避免重新请求和跨域限制
+type archiveInliner struct {
+	page      *rod.Page
+	mimeTypes map[string]string
+	dataURIs  map[string]string
+}
+
+func singleFileHTML(page *rod.Page) (string, error) {
+	tree, err := proto.PageGetResourceTree{}.Call(page)
+	if err != nil {
+		return "", err
+	}
+
+	inliner := &archiveInliner{
+		page:      page,
+		mimeTypes: map[string]string{},
+		dataURIs:  map[string]string{},
+	}
+	if tree.FrameTree != nil {
+		for _, resource := range tree.FrameTree.Resources {
+			if resource.Failed || resource.Canceled {
+				continue
+			}
+			inliner.mimeTypes[resource.URL] = resource.MIMEType
+		}
+	}
+
+	r, err := page.Eval(`() => {
+		const resolve = (value) => {
+			try {
+				return new URL(value, document.baseURI).href;
+			} catch (e) {
+				return '';
+			}
+		};
+		const resources = new Set();
+		const stylesheets = new Set();
+		const cssURL = /url\(\s*(['"]?)([^'")]+)(['"]?)\s*\)/g;
+		const cssImportString = /@import\s+(['"])([^'"]+)\1/g;
+		const collectCSS = (text) => {
+			for (const match of (text || '').replace(cssImportString, '@import url($1$2$1)').matchAll(cssURL)) {
+				resources.add(resolve(match[2]));
+			}
+		};
+
+		document.querySelectorAll('img[src], input[type="image"][src]').forEach((el) => resources.add(resolve(el.getAttribute('src'))));
+		document.querySelectorAll('img').forEach((el) => el.currentSrc && resources.add(el.currentSrc));
+		document.querySelectorAll('video[poster]').forEach((el) => resources.add(resolve(el.getAttribute('poster'))));
+		document.querySelectorAll('link[rel~="icon"][href]').forEach((el) => resources.add(resolve(el.getAttribute('href'))));
+		document.querySelectorAll('link[rel~="stylesheet"][href]').forEach((el) => stylesheets.add(resolve(el.getAttribute('href'))));
+		document.querySelectorAll('style').forEach((el) => collectCSS(el.textContent));
+		document.querySelectorAll('[style]').forEach((el) => collectCSS(el.getAttribute('style')));
+
+		return {
+			resources: Array.from(resources).filter((u) => u && !u.startsWith('data:')),
+			stylesheets: Array.from(stylesheets).filter((u) => u && !u.startsWith('data:')),
+		};
+	}`)
+	if err != nil {
+		return "", err
+	}
+
+	var collected archiveCollected
+	if err := r.Value.Unmarshal(&collected); err != nil {
+		return "", err
+	}
+
+	urlMap := make(map[string]string, len(collected.Resources))
+	for _, u := range collected.Resources {
+		if dataURI, ok := inliner.dataURI(u, 0); ok {
+			urlMap[u] = dataURI
+		}
+	}
+	styles := make(map[string]string, len(collected.Stylesheets))
+	for _, u := range collected.Stylesheets {
+		if css, ok := inliner.stylesheet(u, 0); ok {
+			styles[u] = css
+		}
+	}
+
+	r, err = page.Eval(`(urlMap, styles) => {
+		const resolve = (value) => {
+			try {
+				return new URL(value, document.baseURI).href;
+			} catch (e) {
+				return value;
+			}
+		};
+		// 有 <base> 时剩余的相对地址在删除它之前解析成绝对地址，否则离线打开时会按归档文件解析
+		const hasBase = !!document.querySelector('base[href]');
+		const absolute = (value) => (!hasBase || /^\s*(data:|#|javascript:|mailto:|tel:)/i.test(value) ? value : resolve(value));
+		const cssURL = /url\(\s*(['"]?)([^'")]+)(['"]?)\s*\)/g;
+		const cssImportString = /@import\s+(['"])([^'"]+)\1/g;
+		const rewriteCSS = (text) => (text || '').replace(cssImportString, '@import url($1$2$1)').replace(cssURL, (match, open, value) => {
+			const inlined = urlMap[resolve(value)];
+			if (inlined) {
+				return 'url("' + inlined + '")';
+			}
+			return hasBase ? 'url("' + absolute(value) + '")' : match;
+		});
+		const replaceAttr = (el, name) => {
+			const inlined = urlMap[resolve(el.getAttribute(name))];
+			if (inlined) {
+				el.setAttribute(name, inlined);
+			}
+		};
+
+		const root = document.documentElement.cloneNode(true);
+		root.querySelectorAll('script').forEach((el) => el.remove());
+		root.querySelectorAll('img').forEach((el) => {
+			const src = el.getAttribute('src');
+			const inlined = (src && urlMap[resolve(src)]) || urlMap[el.currentSrc];
+			if (inlined) {
+				el.setAttribute('src', inlined);
+				el.removeAttribute('srcset');
+				el.removeAttribute('sizes');
+				el.removeAttribute('loading');
+			}
+		});
+		root.querySelectorAll('picture source[srcset]').forEach((el) => el.remove());
+		root.querySelectorAll('input[type="image"][src]').forEach((el) => replaceAttr(el, 'src'));
+		root.querySelectorAll('video[poster]').forEach((el) => replaceAttr(el, 'poster'));
+		root.querySelectorAll('link[rel~="icon"][href]').forEach((el) => replaceAttr(el, 'href'));
+		root.querySelectorAll('link[rel~="stylesheet"][href]').forEach((el) => {
+			const css = styles[resolve(el.getAttribute('href'))];
+			if (css === undefined) {
+				return;
+			}
+			const style = document.createElement('style');
+			if (el.media) {
+				style.setAttribute('media', el.media);
+			}
+			style.textContent = css;
+			el.replaceWith(style);
+		});
+		root.querySelectorAll('style').forEach((el) => {
+			el.textContent = rewriteCSS(el.textContent);
+		});
+		root.querySelectorAll('[style]').forEach((el) => {
+			el.setAttribute('style', rewriteCSS(el.getAttribute('style')));
+		});
+		if (hasBase) {
+			root.querySelectorAll('[href], [src], [action], [poster]').forEach((el) => {
+				for (const name of ['href', 'src', 'action', 'poster']) {
+					const value = el.getAttribute(name);
+					if (value !== null) {
+						el.setAttribute(name, absolute(value));
+					}
+				}
+			});
+			root.querySelectorAll('[srcset]').forEach((el) => {
+				el.setAttribute('srcset', el.getAttribute('srcset').split(',').map((candidate) => {
+					const [value, ...descriptors] = candidate.trim().split(/\s+/);
+					return [absolute(value), ...descriptors].join(' ');
+				}).join(', '));
+			});
+		}
+		root.querySelectorAll('base').forEach((el) => el.remove());
+
+		const doctype = document.doctype ? new XMLSerializer().serializeToString(document.doctype) + '\n' : '';
+		return doctype + root.outerHTML;
+	}`, urlMap, styles)
+	if err != nil {
+		return "", err
+	}
+	return r.Value.Str(), nil
+}
+
+// stylesheet 读取样式表并把其中引用的图片、字体和 @import 样式内联
+func (a *archiveInliner) stylesheet(sheetURL string, depth int) (string, bool) {
+	content, err := a.page.GetResource(sheetURL)
+	if err != nil {
+		return "", false
+	}
+	return a.rewriteCSS(string(content), sheetURL, depth), true
+}
+
+func (a *archiveInliner) rewriteCSS(css, baseURL string, depth int) string {
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return css
+	}
+
+	css = cssImportStringPattern.ReplaceAllString(css, `@import url(${1}${2}${1})`)
+	return cssURLPattern.ReplaceAllStringFunc(css, func(match string) string {
+		ref := cssURLPattern.FindStringSubmatch(match)[2]
+		if strings.HasPrefix(ref, "data:") || strings.HasPrefix(ref, "#") {
+			return match
+		}
+		resolved, err := base.Parse(ref)
+		if err != nil {
+			return match
+		}
+		dataURI, ok := a.dataURI(resolved.String(), depth+1)
+		if !ok {
+			// 样式内联到页面后相对地址会按归档文件解析，保留为绝对地址
+			return `url("` + resolved.String() + `")`
+		}
+		return `url("` + dataURI + `")`
+	})
+}
+
+func (a *archiveInliner) dataURI(resourceURL string, depth int) (string, bool) {
+	if dataURI, ok := a.dataURIs[resourceURL]; ok {
+		return dataURI, dataURI != ""
+	}
+	a.dataURIs[resourceURL] = ""
+
+	content, err := a.page.GetResource(resourceURL)
+	if err != nil {
+		return "", false
+	}
+
+	mimeType := a.mimeTypes[resourceURL]
+	if mimeType == "" {
+		mimeType = archiveMIMEType(resourceURL, content)
+	}
+	if strings.HasPrefix(mimeType, "text/css") {
+		if depth >= archiveMaxCSSDepth {
+			return "", false
+		}
+		content = []byte(a.rewriteCSS(string(content), resourceURL, depth))
+	}
+
+	dataURI := "data:" + mimeType + ";base64," + base64.StdEncoding.EncodeToString(content)
+	a.dataURIs[resourceURL] = dataURI
+	return dataURI, true
+}
+
+func archiveMIMEType(resourceURL string, content []byte) string {
+	if parsed, err := url.Parse(resourceURL); err == nil {
+		if mimeType := mime.TypeByExtension(path.Ext(parsed.Path)); mimeType != "" {
+			return mimeType
+		}
+	}
+	return http.DetectContentType(content)
+}
@@ -0,0 +1,128 @@
+package pageviewer
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var archiveTestPNG, _ = base64.StdEncoding.DecodeString("iVBORw0KGgoAAAANSUhEUgAAAAEAAAABCAYAAAAfFcSJAAAADUlEQVR42mP8z8BQDwAEhQGAhKmMIQAAAABJRU5ErkJggg==")
+
+func newArchiveTestServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/style.css":
+			w.Header().Set("Content-Type", "text/css")
+			_, _ = w.Write([]byte(`body { background: url("bg.png"); } h1 { color: red; }`))
+		case "/bg.png", "/logo.png":
+			w.Header().Set("Content-Type", "image/png")
+			_, _ = w.Write(archiveTestPNG)
+		default:
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			_, _ = w.Write([]byte(`<!doctype html>
+<html>
+  <head><link rel="stylesheet" href="/style.css"></head>
+  <body>
+    <h1>archived</h1>
+    <img src="logo.png" alt="logo">
+    <script>window.archived = true;</script>
+  </body>
+</html>`))
+		}
+	}))
+}
+
+func TestArchiveMIMETypeFallsBackToContentSniffing(t *testing.T) {
+	assert.Equal(t, "text/css; charset=utf-8", archiveMIMEType("https://example.com/a.css?v=1", nil))
+	assert.Equal(t, "image/png", archiveMIMEType("https://example.com/image", archiveTestPNG))
+}
+
+func TestArchiveInlinerRewritesStringImports(t *testing.T) {
+	inliner := &archiveInliner{dataURIs: map[string]string{
+		"https://example.com/css/x.css":  "data:text/css;base64,eA==",
+		"https://example.com/css/y.css":  "",
+		"https://example.com/img/bg.png": "",
+	}}
+
+	css := inliner.rewriteCSS(`@import "x.css"; @import 'y.css' screen; body { background: url(../img/bg.png) }`, "https://example.com/css/main.css", 0)
+	assert.Equal(t, `@import url("data:text/css;base64,eA=="); @import url("https://example.com/css/y.css") screen; body { background: url("https://example.com/img/bg.png") }`, css)
+}
+
+func TestClientArchiveRejectsUnknownFormat(t *testing.T) {
+	client := &Client{}
+	_, err := client.Archive(context.Background(), "https://example.com", ArchiveOptions{Format: "zip"})
+	assert.ErrorContains(t, err, "unsupported archive format")
+}
+
+func TestClientArchiveCapturesMHTML(t *testing.T) {
+	s := newArchiveTestServer()
+	defer s.Close()
+
+	client := newTestClient(t, Config{PoolSize: 1, Warmup: 1})
+
+	data, err := client.Archive(context.Background(), s.URL, ArchiveOptions{})
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "multipart/related")
+	assert.Contains(t, string(data), "archived")
+}
+
+func TestClientArchiveInlinesSubresourcesInSingleFile(t *testing.T) {
+	s := newArchiveTestServer()
+	defer s.Close()
+
+	client := newTestClient(t, Config{PoolSize: 1, Warmup: 1})
+
+	data, err := client.Archive(context.Background(), s.URL, ArchiveOptions{Format: ArchiveFormatSingleFile})
+	require.NoError(t, err)
+
+	html := string(data)
+	assert.Contains(t, html, "<!DOCTYPE html>")
+	assert.Contains(t, html, "<h1>archived</h1>")
+	assert.Contains(t, html, `src="data:image/png;base64,`)
+	assert.Contains(t, html, `url("data:image/png;base64,`)
+	assert.Contains(t, html, "color: red")
+	assert.NotContains(t, html, "/style.css")
+	assert.NotContains(t, html, "window.archived")
+}
+
+func TestClientArchiveResolvesURLsAgainstBase(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/assets/imported.css":
+			w.Header().Set("Content-Type", "text/css")
+			_, _ = w.Write([]byte(`h2 { color: blue; }`))
+		case "/assets/logo.png":
+			w.Header().Set("Content-Type", "image/png")
+			_, _ = w.Write(archiveTestPNG)
+		default:
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			_, _ = w.Write([]byte(`<!doctype html>
+<html>
+  <head><base href="/assets/"><style>@import "imported.css";</style></head>
+  <body>
+    <h2>based</h2>
+    <img src="logo.png" alt="logo">
+    <a href="next.html">next</a>
+  </body>
+</html>`))
+		}
+	}))
+	defer s.Close()
+
+	client := newTestClient(t, Config{PoolSize: 1, Warmup: 1})
+
+	data, err := client.Archive(context.Background(), s.URL, ArchiveOptions{Format: ArchiveFormatSingleFile})
+	require.NoError(t, err)
+
+	html := string(data)
+	assert.NotContains(t, html, "<base")
+	assert.Contains(t, html, `@import url("data:text/css`)
+	assert.NotContains(t, html, `"imported.css"`)
+	assert.Contains(t, html, `src="data:image/png;base64,`)
+	assert.Contains(t, html, `href="`+s.URL+`/assets/next.html"`)
+}
@@ -0,0 +1,116 @@
+package pageviewer
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-rod/rod"
+)
+
+const (
+	DefaultAutoScrollMaxSteps      = 20
+	DefaultAutoScrollMaxDuration   = 30 * time.Second
+	DefaultAutoScrollGrowthTimeout = 500 * time.Millisecond
+)
+
+// lazyContentLoadTimeout 写回懒加载属性之后等待图片加载完成的上限
+const lazyContentLoadTimeout = 3 * time.Second
+
+// AutoScrollOptions 无限滚动展开配置，零值字段使用对应的默认值
+type AutoScrollOptions struct {
+	MaxSteps      int           // 最多滚动次数，默认 DefaultAutoScrollMaxSteps
+	MaxDuration   time.Duration // 滚动总时长上限，默认 DefaultAutoScrollMaxDuration
+	StepDelay     time.Duration // 每次滚动后至少停顿的时长，适合限速加载的信息流，默认不停顿
+	GrowthTimeout time.Duration // 停顿之后等待页面高度增长的上限，超过该时长仍未增长即停止，默认 DefaultAutoScrollGrowthTimeout
+}
+
+func (o AutoScrollOptions) withDefaults() AutoScrollOptions {
+	if o.MaxSteps <= 0 {
+		o.MaxSteps = DefaultAutoScrollMaxSteps
+	}
+	if o.MaxDuration <= 0 {
+		o.MaxDuration = DefaultAutoScrollMaxDuration
+	}
+	if o.GrowthTimeout <= 0 {
+		o.GrowthTimeout = DefaultAutoScrollGrowthTimeout
+	}
+	return o
+}
+
+// autoScroll 持续滚动到底部直到页面高度不再增长、达到最大次数或最大时长，然后强制加载懒加载的图片和 iframe，
+// 返回实际滚动次数；达到上限不算错误，只有调用方 ctx 取消时返回 ctx.Err()
+func autoScroll(ctx context.Context, page *rod.Page, opts AutoScrollOptions) (int, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	opts = opts.withDefaults()
+
+	scrollCtx, cancel := context.WithTimeout(ctx, opts.MaxDuration)
+	defer cancel()
+	scrollPage := page.Context(scrollCtx)
+
+	steps := 0
+	for steps < opts.MaxSteps {
+		grown, err := scrollToBottom(scrollPage, opts.StepDelay, opts.GrowthTimeout)
+		if err != nil {
+			if ctx.Err() != nil {
+				return steps, ctx.Err()
+			}
+			if scrollCtx.Err() != nil {
+				break
+			}
+			return steps, err
+		}
+		steps++
+		if !grown {
+			break
+		}
+	}
+
+	if err := loadLazyContent(page.Context(ctx)); err != nil {
+		if ctx.Err() != nil {
+			return steps, ctx.Err()
+		}
+		return steps, err
+	}
+	return steps, nil
+}
+
+// loadLazyContent 把 data-src / data-srcset 等懒加载属性写回 src / srcset，并把 loading="lazy" 改为 eager，
+// 回到页面顶部后最多等待 lazyContentLoadTimeout 让图片加载完成（加载失败也算完成），超时不算错误
+func loadLazyContent(page *rod.Page) error {
+	_, err := page.Eval(`() => {
+		const lazyAttrs = ['data-src', 'data-original', 'data-lazy-src', 'data-url'];
+		const lazySetAttrs = ['data-srcset', 'data-lazy-srcset'];
+		document.querySelectorAll('img, iframe, source, video').forEach((el) => {
+			if (el.getAttribute('loading') === 'lazy') {
+				el.setAttribute('loading', 'eager');
+			}
+			for (const attr of lazyAttrs) {
+				const value = el.getAttribute(attr);
+				if (value && el.getAttribute('src') !== value) {
+					el.setAttribute('src', value);
+					break;
+				}
+			}
+			for (const attr of lazySetAttrs) {
+				const value = el.getAttribute(attr);
+				if (value && el.getAttribute('srcset') !== value) {
+					el.setAttribute('srcset', value);
+					break;
+				}
+			}
+		});
+		window.scrollTo(0, 0);
+	}`)
+	if err != nil {
+		return err
+	}
+
+	err = page.Timeout(lazyContentLoadTimeout).Wait(rod.Eval(`() => Array.from(document.images).every((img) => img.complete)`))
+	if err != nil && errors.Is(err, context.DeadlineExceeded) && page.GetContext().Err() == nil {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,117 @@
+package pageviewer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAutoScrollOptionsWithDefaults(t *testing.T) {
+	opts := AutoScrollOptions{MaxSteps: -1}.withDefaults()
+	assert.Equal(t, DefaultAutoScrollMaxSteps, opts.MaxSteps)
+	assert.Equal(t, DefaultAutoScrollMaxDuration, opts.MaxDuration)
+	assert.Equal(t, DefaultAutoScrollGrowthTimeout, opts.GrowthTimeout)
+
+	assert.Zero(t, opts.StepDelay)
+
+	opts = AutoScrollOptions{MaxSteps: 3, MaxDuration: time.Second, StepDelay: time.Second, GrowthTimeout: 100 * time.Millisecond}.withDefaults()
+	assert.Equal(t, AutoScrollOptions{MaxSteps: 3, MaxDuration: time.Second, StepDelay: time.Second, GrowthTimeout: 100 * time.Millisecond}, opts)
+}
+
+func TestClientAutoScrollExpandsInfiniteFeed(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(`<html><head><title>feed</title></head><body>
+<div id="feed"><div style="height:2000px">item 1</div></div>
+<img id="lazy" src="data:image/gif;base64,R0lGODlhAQABAAAAACw=" data-src="/pixel.gif" loading="lazy">
+<script>
+let count = 1;
+window.addEventListener('scroll', () => {
+	if (count >= 4 || window.innerHeight + window.scrollY < document.body.scrollHeight - 10) {
+		return;
+	}
+	count++;
+	const item = document.createElement('div');
+	item.style.height = '2000px';
+	item.textContent = 'item ' + count;
+	document.getElementById('feed').appendChild(item);
+});
+</script></body></html>`))
+	}))
+	defer s.Close()
+
+	client := newTestClient(t, Config{PoolSize: 1, Warmup: 1})
+
+	html, err := client.HTML(context.Background(), s.URL, WithTraceID("auto-scroll"), WithAutoScroll(AutoScrollOptions{MaxSteps: 10, GrowthTimeout: 300 * time.Millisecond}))
+	require.NoError(t, err)
+	assert.Contains(t, html, "item 4")
+	assert.Contains(t, html, `src="/pixel.gif"`)
+	assert.Contains(t, html, `loading="eager"`)
+
+	trace, ok := client.DebugTrace("auto-scroll")
+	require.True(t, ok)
+	assert.Equal(t, 4, trace.ScrollSteps)
+
+	_, err = client.HTML(context.Background(), s.URL, WithTraceID("auto-scroll-limited"), WithAutoScroll(AutoScrollOptions{MaxSteps: 2, GrowthTimeout: 300 * time.Millisecond}))
+	require.NoError(t, err)
+	trace, ok = client.DebugTrace("auto-scroll-limited")
+	require.True(t, ok)
+	assert.Equal(t, 2, trace.ScrollSteps)
+}
+
+func TestClientAutoScrollStepDelayWaitsForThrottledFeed(t *testing.T) {
+	var imageLoaded atomic.Bool
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow.gif" {
+			time.Sleep(500 * time.Millisecond)
+			w.Header().Set("Content-Type", "image/gif")
+			_, _ = w.Write([]byte("GIF89a"))
+			imageLoaded.Store(true)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(`<html><head><title>throttled</title></head><body>
+<div id="feed"><div style="height:2000px">item 1</div></div>
+<img id="lazy" src="data:image/gif;base64,R0lGODlhAQABAAAAACw=" data-src="/slow.gif">
+<script>
+let count = 1;
+let loading = false;
+window.addEventListener('scroll', () => {
+	if (loading || count >= 3 || window.innerHeight + window.scrollY < document.body.scrollHeight - 10) {
+		return;
+	}
+	loading = true;
+	// 限速的信息流在滚动 600ms 之后才追加内容
+	setTimeout(() => {
+		count++;
+		const item = document.createElement('div');
+		item.style.height = '2000px';
+		item.textContent = 'item ' + count;
+		document.getElementById('feed').appendChild(item);
+		loading = false;
+	}, 600);
+});
+</script></body></html>`))
+	}))
+	defer s.Close()
+
+	client := newTestClient(t, Config{PoolSize: 1, Warmup: 1})
+
+	html, err := client.HTML(context.Background(), s.URL, WithAutoScroll(AutoScrollOptions{MaxSteps: 10, GrowthTimeout: 100 * time.Millisecond}))
+	require.NoError(t, err)
+	assert.NotContains(t, html, "item 2")
+
+	imageLoaded.Store(false)
+	html, err = client.HTML(context.Background(), s.URL, WithAutoScroll(AutoScrollOptions{MaxSteps: 10, StepDelay: 700 * time.Millisecond, GrowthTimeout: 100 * time.Millisecond}))
+	require.NoError(t, err)
+	assert.Contains(t, html, "item 3")
+
+	// 写回 src 的图片加载完成之后才开始抽取
+	assert.True(t, imageLoaded.Load())
+}
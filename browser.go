@@ -2,6 +2,7 @@ package pageviewer
 
 import (
 	"context"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"github.com/LubyRuffy/pageviewer/js"
@@ -14,24 +15,21 @@ import (
 	htmltomarkdown "github.com/JohannesKaufmann/html-to-markdown/v2"
 	"github.com/JohannesKaufmann/html-to-markdown/v2/converter"
 	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/devices"
 	"github.com/go-rod/rod/lib/launcher"
 	"github.com/go-rod/rod/lib/proto"
 	"github.com/go-rod/stealth"
 )
 
 var (
-	defaultBrowser                   *Browser
-	once                             sync.Once
-	newBrowserWithOptions            = NewBrowser
-	browserCloseWaitTimeout          = 2 * time.Second
-	browserKillWaitTimeout           = 3 * time.Second
-	browserProcessPollInterval       = 50 * time.Millisecond
-	browserWaitLoadTimeoutCap        = 15 * time.Second
-	browserWaitIdleTimeoutCap        = 5 * time.Second
-	browserWaitRequestIdleTimeoutCap = 500 * time.Millisecond
-	browserWaitDOMStableTimeoutCap   = 2 * time.Second
-	browserLeaklessLockWaitTimeout   = 500 * time.Millisecond
-	browserLeaklessLockPollInterval  = 25 * time.Millisecond
+	defaultBrowser                  *Browser
+	once                            sync.Once
+	newBrowserWithOptions           = NewBrowser
+	browserCloseWaitTimeout         = 2 * time.Second
+	browserKillWaitTimeout          = 3 * time.Second
+	browserProcessPollInterval      = 50 * time.Millisecond
+	browserLeaklessLockWaitTimeout  = 500 * time.Millisecond
+	browserLeaklessLockPollInterval = 25 * time.Millisecond
 )
 
 const browserLeaklessDefaultLockPort = 2978
@@ -41,6 +39,13 @@ type PageOptions struct {
 	beforeRequest      func(page *rod.Page) error // 在请求之前的回调，做一些
 	removeInvisibleDiv bool                       // 是否移除不可见的div
 	blockSubresources  bool                       // 是否阻断主文档之外的请求
+	waitFor            []WaitCondition            // 默认稳定等待之后额外等待的条件
+	waitStrategy       WaitStrategy               // 默认稳定等待的策略，零值使用 WaitStrategyBalanced()
+	actions            []Action                   // 导航完成后、抽取之前执行的交互动作
+	recordAction       func(ActionResult)         // 记录动作执行结果
+	autoScroll         *AutoScrollOptions         // 抽取之前滚动展开无限滚动和懒加载内容
+	recordScrollSteps  func(int)                  // 记录实际滚动次数
+	popupPage          func() (*rod.Page, error)  // 返回请求期间打开的新页面，设置后改为在新页面上抽取
 }
 
 type Browser struct {
@@ -55,7 +60,8 @@ type Browser struct {
 
 type documentResponseResult struct {
 	response *proto.NetworkResponseReceived
-	body     string
+	data     []byte // 主文档未经浏览器解码的原始字节
+	decoded  bool   // data 取自 Network.getResponseBody，已由浏览器解码为 UTF-8
 	err      error
 }
 
@@ -68,6 +74,15 @@ func (b *Browser) GetPage() (*rod.Page, error) {
 	return stealth.Page(b.Browser)
 }
 
+// baselineDevice 返回新页面默认模拟的设备，请求级设备模拟结束后恢复到该设备；
+// 用户浏览器模式不模拟设备，其他模式沿用 rod 的默认设备
+func (b *Browser) baselineDevice() devices.Device {
+	if b == nil || b.UseUserMode {
+		return devices.Clear
+	}
+	return devices.LaptopWithMDPIScreen.Landscape()
+}
+
 func (b *Browser) Close() error {
 	if b == nil {
 		return nil
@@ -152,43 +167,48 @@ func waitForProcessTreeExit(pid int, timeout time.Duration) (bool, error) {
 }
 
 func (b *Browser) WaitPage(page *rod.Page, po *PageOptions) error {
+	_, err := b.waitPage(page, po)
+	return err
+}
+
+// waitPage 按 WaitStrategy 分阶段等待，返回 po.waitTimeout 中尚未用掉的时长，供随后的 WithWaitFor 条件共享；
+// 未设置 po.waitTimeout 时各阶段都会跳过，条件使用 DefaultWaitStableTimeout
+func (b *Browser) waitPage(page *rod.Page, po *PageOptions) (time.Duration, error) {
 	s := time.Now()
-	err := page.Timeout(min(po.waitTimeout, browserWaitLoadTimeoutCap)).WaitLoad()
-	if err != nil {
-		if !errors.Is(err, context.DeadlineExceeded) {
-			return err
+	strategy := po.waitStrategy.or(WaitStrategyBalanced())
+	remaining := func() time.Duration {
+		return po.waitTimeout - time.Since(s)
+	}
+
+	if timeout, ok := phaseTimeout(strategy.Load, remaining()); ok {
+		if err := page.Timeout(timeout).WaitLoad(); err != nil && !errors.Is(err, context.DeadlineExceeded) {
+			return 0, err
 		}
 	}
 
-	err = page.WaitIdle(min(po.waitTimeout-time.Since(s), browserWaitIdleTimeoutCap))
-	if err != nil {
-		if !errors.Is(err, context.DeadlineExceeded) {
-			return err
+	if timeout, ok := phaseTimeout(strategy.Idle, remaining()); ok {
+		if err := page.WaitIdle(timeout); err != nil && !errors.Is(err, context.DeadlineExceeded) {
+			return 0, err
 		}
 	}
 
 	// 等待请求都有响应
-	page.WaitRequestIdle(min(po.waitTimeout-time.Since(s), browserWaitRequestIdleTimeoutCap), nil, []string{
-		``, // 排除广告部分
-	}, nil)()
+	if timeout, ok := phaseTimeout(strategy.RequestIdle, remaining()); ok {
+		page.WaitRequestIdle(timeout, nil, []string{
+			``, // 排除广告部分
+		}, nil)()
+	}
 
-	// 把差异调整为0.2，放大，不然会一直等待
-	if po.waitTimeout-time.Since(s) > 0 {
-		err = page.WaitDOMStable(min(po.waitTimeout-time.Since(s), browserWaitDOMStableTimeoutCap), 0.2)
-		if err != nil {
-			if !errors.Is(err, context.DeadlineExceeded) {
-				return err
-			}
+	if timeout, ok := phaseTimeout(strategy.DOMStable, remaining()); ok {
+		if err := page.WaitDOMStable(timeout, strategy.domStableDiff()); err != nil && !errors.Is(err, context.DeadlineExceeded) {
+			return 0, err
 		}
 	}
 
-	//err = page.WaitStable(vo.waitTimeout - time.Since(s))
-	//if err != nil {
-	//	if !errors.Is(err, context.DeadlineExceeded) {
-	//		return err
-	//	}
-	//}
-	return err
+	if po.waitTimeout <= 0 {
+		return DefaultWaitStableTimeout, nil
+	}
+	return max(remaining(), 0), nil
 }
 
 func (b *Browser) waitPageReady(u string, po *PageOptions) (*rod.Page, error) {
@@ -197,7 +217,7 @@ func (b *Browser) waitPageReady(u string, po *PageOptions) (*rod.Page, error) {
 		return nil, err
 	}
 
-	if _, err := b.navigatePage(context.Background(), page, u, po); err != nil {
+	if _, _, err := b.navigatePage(context.Background(), page, u, po); err != nil {
 		_ = page.Close()
 		return nil, err
 	}
@@ -208,7 +228,7 @@ func newUnsupportedDOMContentError(u, mimeType string) error {
 	return fmt.Errorf("%w: no html content:%s. The url's MIMEType is:%s", ErrUnsupportedContentType, u, mimeType)
 }
 
-func waitForMainDocumentResponse(ctx context.Context, page *rod.Page, includeBody bool) (func() (documentResponseResult, error), func()) {
+func waitForMainDocumentResponse(ctx context.Context, page *rod.Page) (func() (documentResponseResult, error), func()) {
 	if ctx == nil {
 		ctx = context.Background()
 	}
@@ -233,11 +253,7 @@ func waitForMainDocumentResponse(ctx context.Context, page *rod.Page, includeBod
 					return false
 				}
 
-				result := documentResponseResult{response: response}
-				if includeBody {
-					result.body, result.err = readResponseBody(waitPage, response)
-				}
-				resultCh <- result
+				resultCh <- documentResponseResult{response: response}
 				sent = true
 				return true
 			},
@@ -268,37 +284,35 @@ func waitForMainDocumentResponse(ctx context.Context, page *rod.Page, includeBod
 		}
 }
 
-func (b *Browser) navigatePage(ctx context.Context, page *rod.Page, u string, po *PageOptions) (*proto.NetworkResponseReceived, error) {
+// navigatePage 导航并等待页面稳定，返回主文档响应和剩余的等待时长
+func (b *Browser) navigatePage(ctx context.Context, page *rod.Page, u string, po *PageOptions) (*proto.NetworkResponseReceived, time.Duration, error) {
 	if po.beforeRequest != nil {
 		if err := po.beforeRequest(page); err != nil {
-			return nil, err
+			return nil, 0, err
 		}
 	}
 
-	waitDocument, stopWaiting := waitForMainDocumentResponse(ctx, page, false)
+	waitDocument, stopWaiting := waitForMainDocumentResponse(ctx, page)
 	defer stopWaiting()
 
 	if err := page.Navigate(u); err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
 	result, err := waitDocument()
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 	response := result.response
 	if response == nil {
-		return nil, ErrNavigationFailed
+		return nil, 0, ErrNavigationFailed
 	}
 	if !isTextContentType(response.Response.MIMEType) {
-		return response, newUnsupportedDOMContentError(u, response.Response.MIMEType)
+		return response, 0, newUnsupportedDOMContentError(u, response.Response.MIMEType)
 	}
 
-	if err := b.WaitPage(page, po); err != nil {
-		return response, err
-	}
-
-	return response, nil
+	remaining, err := b.waitPage(page, po)
+	return response, remaining, err
 }
 
 func (b *Browser) navigateTextPage(ctx context.Context, page *rod.Page, u string, po *PageOptions) (documentResponseResult, error) {
@@ -308,13 +322,13 @@ func (b *Browser) navigateTextPage(ctx context.Context, page *rod.Page, u string
 		}
 	}
 
-	stopBlocker, err := blockSubresources(page, po)
+	documentBody, stopInterceptor, err := interceptDocumentBody(page, po)
 	if err != nil {
 		return documentResponseResult{}, err
 	}
-	defer stopBlocker()
+	defer stopInterceptor()
 
-	waitDocument, stopWaiting := waitForMainDocumentResponse(ctx, page, true)
+	waitDocument, stopWaiting := waitForMainDocumentResponse(ctx, page)
 	defer stopWaiting()
 
 	if err := page.Navigate(u); err != nil {
@@ -331,6 +345,9 @@ func (b *Browser) navigateTextPage(ctx context.Context, page *rod.Page, u string
 	if !isTextContentType(result.response.Response.MIMEType) {
 		return result, ErrUnsupportedContentType
 	}
+	if result.data, result.decoded, err = documentBody(ctx, result.response.RequestID); err != nil {
+		return documentResponseResult{}, err
+	}
 	if err := b.WaitPage(page, po); err != nil {
 		return documentResponseResult{}, err
 	}
@@ -338,32 +355,83 @@ func (b *Browser) navigateTextPage(ctx context.Context, page *rod.Page, u string
 	return result, nil
 }
 
-func blockSubresources(page *rod.Page, po *PageOptions) (func(), error) {
-	if po == nil || !po.blockSubresources {
-		return func() {}, nil
-	}
-
-	router := page.HijackRequests()
-	if err := router.Add("*", "", func(h *rod.Hijack) {
-		if h.Request.Type() == proto.NetworkResourceTypeDocument {
-			h.ContinueRequest(&proto.FetchContinueRequest{})
-			return
+// interceptDocumentBody 在响应阶段读取主文档未经浏览器解码的原始字节后放行，
+// po.blockSubresources 为 true 时同时在请求阶段阻断主文档之外的请求；
+// file://、data: 或 Service Worker 返回的主文档不会在响应阶段暂停，此时直接改用 Network.getResponseBody 读取
+func interceptDocumentBody(page *rod.Page, po *PageOptions) (func(ctx context.Context, requestID proto.NetworkRequestID) ([]byte, bool, error), func(), error) {
+	block := po != nil && po.blockSubresources
+	patterns := []*proto.FetchRequestPattern{{
+		URLPattern:   "*",
+		ResourceType: proto.NetworkResourceTypeDocument,
+		RequestStage: proto.FetchRequestStageResponse,
+	}}
+	if block {
+		patterns = append(patterns, &proto.FetchRequestPattern{URLPattern: "*", RequestStage: proto.FetchRequestStageRequest})
+	}
+	if err := (proto.FetchEnable{Patterns: patterns}).Call(page); err != nil {
+		return nil, nil, err
+	}
+
+	type bodyResult struct {
+		data []byte
+		err  error
+	}
+	interceptPage, cancel := page.WithCancel()
+	bodyCh := make(chan bodyResult, 1)
+	captured := false
+	wait := interceptPage.EachEvent(func(e *proto.FetchRequestPaused) {
+		switch {
+		case e.ResponseStatusCode == nil && e.ResponseErrorReason == "":
+			if block && e.ResourceType != proto.NetworkResourceTypeDocument {
+				_ = proto.FetchFailRequest{RequestID: e.RequestID, ErrorReason: proto.NetworkErrorReasonBlockedByClient}.Call(interceptPage)
+				return
+			}
+		case captured || e.ResponseErrorReason != "" || isRedirectResponse(*e.ResponseStatusCode, e.ResponseHeaders):
+		default:
+			// 第一个非重定向的文档响应就是主文档，iframe 只会在主文档放行之后才开始加载
+			captured = true
+			var result bodyResult
+			if contentType := newFetchHeader(e.ResponseHeaders).Get("Content-Type"); contentType == "" || isTextContentType(mediaType(contentType)) {
+				result.data, result.err = getFetchResponseBody(interceptPage, e.RequestID)
+			}
+			bodyCh <- result
 		}
-		h.Response.Fail(proto.NetworkErrorReasonBlockedByClient)
-	}); err != nil {
-		return nil, err
-	}
-
+		_ = proto.FetchContinueRequest{RequestID: e.RequestID}.Call(interceptPage)
+	})
 	done := make(chan struct{})
 	go func() {
 		defer close(done)
-		router.Run()
+		wait()
 	}()
 
-	return func() {
-		_ = router.Stop()
-		<-done
-	}, nil
+	return func(ctx context.Context, requestID proto.NetworkRequestID) ([]byte, bool, error) {
+			// 暂停的响应在放行之前就已经写入 bodyCh，主文档加载完成后调用时 bodyCh 为空说明主文档没有经过响应阶段
+			select {
+			case <-ctx.Done():
+				return nil, false, ctx.Err()
+			case result := <-bodyCh:
+				return result.data, false, result.err
+			default:
+				// 主文档已经是文本类型，Network.getResponseBody 返回浏览器解码后的 UTF-8
+				data, err := getResponseBodyBytes(page.Context(ctx), requestID)
+				return data, true, err
+			}
+		}, func() {
+			cancel()
+			<-done
+			_ = proto.FetchDisable{}.Call(page)
+		}, nil
+}
+
+func getFetchResponseBody(page *rod.Page, requestID proto.FetchRequestID) ([]byte, error) {
+	reply, err := (proto.FetchGetResponseBody{RequestID: requestID}).Call(page)
+	if err != nil {
+		return nil, err
+	}
+	if reply.Base64Encoded {
+		return base64.StdEncoding.DecodeString(reply.Body)
+	}
+	return []byte(reply.Body), nil
 }
 
 func removeInvisibleElements(page *rod.Page) error {
@@ -458,10 +526,36 @@ func (b *Browser) runPage(ctx context.Context, page *rod.Page, u string, po *Pag
 		po = newDefaultVisitOptions().PageOptions
 	}
 
-	response, e := b.navigatePage(ctx, page, u, po)
+	response, waitRemaining, e := b.navigatePage(ctx, page, u, po)
 	if e != nil {
 		return response, true, e
 	}
+	if err := runActions(ctx, page, po.waitTimeout, po.actions, po.recordAction); err != nil {
+		return response, false, err
+	}
+	if po.autoScroll != nil {
+		steps, err := autoScroll(ctx, page, *po.autoScroll)
+		if po.recordScrollSteps != nil {
+			po.recordScrollSteps(steps)
+		}
+		if err != nil {
+			return response, false, err
+		}
+	}
+	if po.popupPage != nil {
+		popup, err := po.popupPage()
+		if err != nil {
+			return response, false, err
+		}
+		if popup != nil {
+			if page, response, err = b.followPopup(ctx, popup, po); err != nil {
+				return response, false, err
+			}
+		}
+	}
+	if err := waitForConditions(ctx, page, waitRemaining, po.waitFor); err != nil {
+		return response, false, err
+	}
 
 	if po.removeInvisibleDiv {
 		// 执行 JavaScript 检测并删除不可见的 div
@@ -581,6 +675,10 @@ func readResponseBody(page *rod.Page, response *proto.NetworkResponseReceived) (
 	if response == nil {
 		return "", nil
 	}
+	if response.RequestID == "" && response.Response != nil {
+		// 跟随的新页面没有 Network 事件记录，按 frame 和地址从资源树读取主文档
+		return getFrameResourceContent(page, response.FrameID, response.Response.URL)
+	}
 
 	var lastErr error
 	for range 10 {
@@ -598,11 +696,36 @@ func readResponseBody(page *rod.Page, response *proto.NetworkResponseReceived) (
 }
 
 func getResponseBody(page *rod.Page, requestID proto.NetworkRequestID) (string, error) {
-	reply, err := (proto.NetworkGetResponseBody{RequestID: requestID}).Call(page)
+	body, err := getResponseBodyBytes(page, requestID)
 	if err != nil {
 		return "", err
 	}
-	return reply.Body, nil
+	return string(body), nil
+}
+
+// getFrameResourceContent 通过 Page.getResourceContent 读取 frame 资源树中的文档内容
+func getFrameResourceContent(page *rod.Page, frameID proto.PageFrameID, u string) (string, error) {
+	reply, err := (proto.PageGetResourceContent{FrameID: frameID, URL: u}).Call(page)
+	if err != nil {
+		return "", err
+	}
+	if reply.Base64Encoded {
+		body, err := base64.StdEncoding.DecodeString(reply.Content)
+		return string(body), err
+	}
+	return reply.Content, nil
+}
+
+// getResponseBodyBytes 读取响应体原始字节，二进制响应会按 Base64Encoded 解码
+func getResponseBodyBytes(page *rod.Page, requestID proto.NetworkRequestID) ([]byte, error) {
+	reply, err := (proto.NetworkGetResponseBody{RequestID: requestID}).Call(page)
+	if err != nil {
+		return nil, err
+	}
+	if reply.Base64Encoded {
+		return base64.StdEncoding.DecodeString(reply.Body)
+	}
+	return []byte(reply.Body), nil
 }
 
 func fillReadabilityArticle(page *rod.Page, url string, article *ReadabilityArticleWithMarkdown) error {
@@ -642,37 +765,6 @@ func fillReadabilityArticle(page *rod.Page, url string, article *ReadabilityArti
 	return err
 }
 
-func collectLinks(page *rod.Page) (string, error) {
-	elements, err := page.Elements("a")
-	if err != nil {
-		return "", err
-	}
-
-	var links []string
-	for _, el := range elements {
-		t, err := el.Text()
-		if err != nil {
-			continue
-		}
-		if t == "" {
-			continue
-		}
-
-		href, err := el.Property("href")
-		if err != nil {
-			continue
-		}
-		if !href.Nil() && href.String() != "" {
-			link := href.String()
-			if !strings.HasPrefix(link, "javascript:") {
-				links = append(links, fmt.Sprintf(`<a href="%s">`+t+`</a>`, href.String()))
-			}
-		}
-	}
-
-	return strings.Join(links, "\n"), nil
-}
-
 func resolveLeaklessEnabled(requested bool, lockPort int, timeout time.Duration, pollInterval time.Duration) bool {
 	if !requested {
 		return false
@@ -0,0 +1,57 @@
+package pageviewer
+
+import (
+	"bytes"
+	"mime"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html/charset"
+	"golang.org/x/text/encoding"
+)
+
+var xmlDeclarationEncoding = regexp.MustCompile(`^\s*<\?xml[^>]*?\sencoding\s*=\s*["']([A-Za-z0-9._:-]+)["']`)
+
+var charsetBOMs = map[string][]byte{
+	"utf-8":    {0xef, 0xbb, 0xbf},
+	"utf-16be": {0xfe, 0xff},
+	"utf-16le": {0xff, 0xfe},
+}
+
+// detectCharset 依次按 BOM、Content-Type 的 charset 参数、XML 声明、<meta charset> 确定编码；
+// 都没有时 JSON 按 UTF-8 处理，其他内容是合法 UTF-8 时按 UTF-8，否则与浏览器一样回退到 windows-1252。
+// 返回的名称为 WHATWG 编码标准中的规范名，例如 gbk、big5、shift_jis、windows-1251
+func detectCharset(data []byte, contentType string) (encoding.Encoding, string) {
+	if e, name, certain := charset.DetermineEncoding(data, contentType); certain {
+		return e, name
+	}
+
+	head := data[:min(len(data), 1024)]
+	if m := xmlDeclarationEncoding.FindSubmatch(head); m != nil {
+		if e, name := charset.Lookup(string(m[1])); e != nil {
+			return e, name
+		}
+	}
+
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil && (mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")) {
+		return encoding.Nop, "utf-8"
+	}
+
+	e, name, _ := charset.DetermineEncoding(data, "")
+	return e, name
+}
+
+// decodeCharset 把响应体按 detectCharset 的结果转成 UTF-8，并去掉 BOM
+func decodeCharset(data []byte, contentType string) (string, string, error) {
+	e, name := detectCharset(data, contentType)
+	data = bytes.TrimPrefix(data, charsetBOMs[name])
+	if e == encoding.Nop {
+		return string(data), name, nil
+	}
+
+	decoded, err := e.NewDecoder().Bytes(data)
+	if err != nil {
+		return "", name, err
+	}
+	return string(decoded), name, nil
+}
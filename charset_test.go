@@ -0,0 +1,112 @@
+package pageviewer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/traditionalchinese"
+)
+
+func mustEncode(t *testing.T, e encoding.Encoding, s string) []byte {
+	t.Helper()
+	data, err := e.NewEncoder().Bytes([]byte(s))
+	require.NoError(t, err)
+	return data
+}
+
+func TestDecodeCharsetDetectsEncoding(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        []byte
+		contentType string
+		want        string
+		charset     string
+	}{
+		{
+			name:        "content type header",
+			data:        mustEncode(t, simplifiedchinese.GBK, "你好世界"),
+			contentType: "text/plain; charset=GB2312",
+			want:        "你好世界",
+			charset:     "gbk",
+		},
+		{
+			name:    "meta charset",
+			data:    mustEncode(t, traditionalchinese.Big5, `<html><head><meta charset="big5"></head><body>繁體中文</body></html>`),
+			want:    `<html><head><meta charset="big5"></head><body>繁體中文</body></html>`,
+			charset: "big5",
+		},
+		{
+			name:        "meta http-equiv",
+			data:        mustEncode(t, japanese.ShiftJIS, `<meta http-equiv="Content-Type" content="text/html; charset=Shift_JIS">日本語`),
+			contentType: "text/html",
+			want:        `<meta http-equiv="Content-Type" content="text/html; charset=Shift_JIS">日本語`,
+			charset:     "shift_jis",
+		},
+		{
+			name:        "xml declaration",
+			data:        mustEncode(t, charmap.Windows1251, `<?xml version="1.0" encoding="windows-1251"?><title>Привет</title>`),
+			contentType: "application/xml",
+			want:        `<?xml version="1.0" encoding="windows-1251"?><title>Привет</title>`,
+			charset:     "windows-1251",
+		},
+		{
+			name:        "bom wins over header",
+			data:        append([]byte{0xef, 0xbb, 0xbf}, "标题"...),
+			contentType: "text/html; charset=gbk",
+			want:        "标题",
+			charset:     "utf-8",
+		},
+		{
+			name:        "json defaults to utf-8",
+			data:        []byte(`{"ok":true}`),
+			contentType: "application/json",
+			want:        `{"ok":true}`,
+			charset:     "utf-8",
+		},
+		{
+			name:    "valid utf-8 without hints",
+			data:    []byte("纯文本"),
+			want:    "纯文本",
+			charset: "utf-8",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, name, err := decodeCharset(tt.data, tt.contentType)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, body)
+			assert.Equal(t, tt.charset, name)
+		})
+	}
+}
+
+func TestClientRawTextTranscodesToUTF8(t *testing.T) {
+	page := `<html><head><meta charset="gbk"><title>中文标题</title></head><body><p>简体中文正文</p></body></html>`
+	encoded := mustEncode(t, simplifiedchinese.GBK, page)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write(encoded)
+	}))
+	defer s.Close()
+
+	client := newTestClient(t, Config{PoolSize: 1, Warmup: 1})
+
+	resp, err := client.RawText(context.Background(), s.URL)
+	require.NoError(t, err)
+	assert.Equal(t, page, resp.Body)
+	assert.Equal(t, "gbk", resp.Charset)
+	assert.Nil(t, resp.RawBody)
+
+	resp, err = client.RawText(context.Background(), s.URL, WithUndecodedBody(true))
+	require.NoError(t, err)
+	assert.Equal(t, page, resp.Body)
+	assert.Equal(t, encoded, resp.RawBody)
+}
@@ -19,10 +19,15 @@ var (
 )
 
 type Stats struct {
-	TotalWorkers int
-	IdleWorkers  int
-	RecentTraces int
-	LastError    string
+	TotalWorkers       int
+	IdleWorkers        int
+	RecentTraces       int
+	LastError          string
+	PopupsOpened       int64 // worker 页面累计打开的新页面数
+	PopupsClosed       int64 // 归还 worker 时累计关闭的遗留新页面数
+	RecycledByRequests int64 // 达到 MaxRequestsPerWorker 被回收的 worker 数
+	RecycledByAge      int64 // 达到 MaxWorkerAge 被回收的 worker 数
+	RecycledByMemory   int64 // 超过 MaxWorkerHeapSize 被回收的 worker 数
 }
 
 type Client struct {
@@ -33,8 +38,14 @@ type Client struct {
 	closed         atomic.Bool
 	fillScheduled  atomic.Bool
 	nextWorkerID   atomic.Int32
+	popupsOpened   atomic.Int64
+	popupsClosed   atomic.Int64
 	totalWorkers   atomic.Int32
 	acquireTimeout time.Duration
+	waitStrategy   WaitStrategy
+	dialogPolicy   DialogPolicy
+	workerReset    WorkerResetOptions
+	recycler       *workerRecycler
 	ownsBrowser    bool
 	repairWorkers  bool
 	closeCh        chan struct{}
@@ -43,6 +54,7 @@ type Client struct {
 	fillMu         sync.Mutex
 	mu             sync.Mutex
 	workers        []*worker
+	sessions       []*Session
 }
 
 func Start(ctx context.Context, cfg Config) (*Client, error) {
@@ -57,12 +69,21 @@ func Start(ctx context.Context, cfg Config) (*Client, error) {
 		return nil, err
 	}
 
+	return startPool(ctx, browser, cfg, newTraceRecorder(defaultTraceCapacity))
+}
+
+// startPool 在 browser 上创建 Client 并预热 worker，Client 负责关闭 browser，失败时一并关闭
+func startPool(ctx context.Context, browser *Browser, cfg Config, traces *traceRecorder) (_ *Client, err error) {
 	client := &Client{
 		browser:        browser,
 		pool:           newWorkerPool(cfg.PoolSize),
-		traces:         newTraceRecorder(defaultTraceCapacity),
+		traces:         traces,
 		poolSize:       cfg.PoolSize,
 		acquireTimeout: cfg.AcquireTimeout,
+		waitStrategy:   cfg.WaitStrategy,
+		dialogPolicy:   cfg.DialogPolicy,
+		workerReset:    cfg.WorkerReset,
+		recycler:       newWorkerRecycler(cfg),
 		ownsBrowser:    true,
 		repairWorkers:  true,
 		closeCh:        make(chan struct{}),
@@ -111,7 +132,17 @@ func (c *Client) Close() error {
 	c.stateMu.Unlock()
 
 	c.inflight.Wait()
-	return c.closeResources()
+
+	c.mu.Lock()
+	sessions := c.sessions
+	c.sessions = nil
+	c.mu.Unlock()
+
+	var errs []error
+	for _, session := range sessions {
+		errs = append(errs, session.Client.Close())
+	}
+	return errors.Join(append(errs, c.closeResources())...)
 }
 
 func (c *Client) Stats() Stats {
@@ -128,12 +159,20 @@ func (c *Client) Stats() Stats {
 
 	recentTraces, lastError := c.traceStats()
 
-	return Stats{
+	stats := Stats{
 		TotalWorkers: int(c.totalWorkers.Load()),
 		IdleWorkers:  idleWorkers,
 		RecentTraces: recentTraces,
 		LastError:    lastError,
+		PopupsOpened: c.popupsOpened.Load(),
+		PopupsClosed: c.popupsClosed.Load(),
+	}
+	if c.recycler != nil {
+		stats.RecycledByRequests = c.recycler.byRequests.Load()
+		stats.RecycledByAge = c.recycler.byAge.Load()
+		stats.RecycledByMemory = c.recycler.byMemory.Load()
 	}
+	return stats
 }
 
 func (c *Client) DebugTrace(id string) (Trace, bool) {
@@ -247,10 +286,19 @@ func newWarmWorker(ctx context.Context, browser *Browser, id int) (*worker, erro
 		return nil, err
 	}
 
+	dialogs := newDialogHandler(result.page)
+	popups := newPopupTracker(browser.Browser, result.page)
 	return &worker{
-		id:      id,
-		page:    result.page,
-		closeFn: result.page.Close,
+		id:   id,
+		page: result.page,
+		closeFn: func() error {
+			dialogs.close()
+			popups.close()
+			return result.page.Close()
+		},
+		dialogs:   dialogs,
+		popups:    popups,
+		createdAt: time.Now(),
 	}, nil
 }
 
@@ -306,9 +354,40 @@ func (c *Client) closeResources() error {
 	return errors.Join(errs...)
 }
 
-func (c *Client) visitWithOptions(ctx context.Context, url string, ro RequestOptions, reuseWorker bool, onPageLoad func(page *rod.Page, response *proto.NetworkResponseReceived) error) (err error) {
-	trace := c.beginTrace(ro.TraceID, traceModeDOM, url)
+func (c *Client) visitWithOptions(ctx context.Context, url string, ro RequestOptions, reuseWorker bool, onPageLoad func(page *rod.Page, response *proto.NetworkResponseReceived) error) error {
+	return c.runOnWorker(ctx, url, ro, traceModeDOM, func(req *workerRequest) (*proto.NetworkResponseReceived, error) {
+		po := c.pageOptions(ro)
+		po.recordAction = req.trace.addAction
+		po.recordScrollSteps = req.trace.setScrollSteps
+		if ro.FollowPopup {
+			po.popupPage = req.worker.popups.latest
+		}
+		response, pageBroken, err := c.browser.runPage(ctx, req.page, url, po, onPageLoad)
+		if pageBroken || !reuseWorker {
+			req.state = workerStateBroken
+		}
+		return response, err
+	})
+}
+
+// workerRequest 在借用的 worker 上执行的一次请求，run 把 state 置为 workerStateBroken 表示 worker 不能再复用
+type workerRequest struct {
+	worker  *worker
+	page    *rod.Page // 绑定到本次请求的页面，导航和回调都使用它
+	trace   *traceSession
+	capture *networkCapture // 未开启 WARC / 网络日志时为 nil
+	state   workerState
+}
+
+// runOnWorker 借用 worker 执行 run，统一处理 trace、请求级覆盖项、网络记录，以及按 state 归还、回收或修复 worker；
+// run 返回主文档响应，用于写入 trace
+func (c *Client) runOnWorker(ctx context.Context, url string, ro RequestOptions, mode string, run func(req *workerRequest) (*proto.NetworkResponseReceived, error)) (err error) {
+	trace := c.beginTrace(ro.TraceID, mode, url)
+	var capture *networkCapture
 	defer func() {
+		if warcErr := finishRequestCapture(ro, capture, &trace, err); warcErr != nil && err == nil {
+			err = warcErr
+		}
 		trace.finish(err)
 	}()
 
@@ -328,7 +407,7 @@ func (c *Client) visitWithOptions(ctx context.Context, url string, ro RequestOpt
 	defer stopAcquire()
 
 	acquireStart := time.Now()
-	worker, release, err := c.pool.acquire(acquireCtx, c.acquireWorkerTimeout(ctx, ro))
+	worker, release, err := c.acquireWorker(ctx, acquireCtx, ro)
 	trace.setAcquireWait(time.Since(acquireStart))
 	if err != nil {
 		if errors.Is(err, context.Canceled) && c.closed.Load() && ctx.Err() == nil {
@@ -342,27 +421,50 @@ func (c *Client) visitWithOptions(ctx context.Context, url string, ro RequestOpt
 		return ErrClosed
 	}
 
-	state := workerStateReady
+	req := &workerRequest{worker: worker, trace: &trace, state: workerStateReady}
 	defer func() {
-		release(state)
-		if state == workerStateBroken {
+		release(req.state)
+		if req.state == workerStateReady {
+			return
+		}
+		if req.state == workerStateBroken {
 			trace.markBrokenWorker()
-			if c.repairWorkers {
-				c.scheduleRepair(worker)
-				return
-			}
-			c.retireWorker(worker)
 		}
+		if ro.IsolatedContext {
+			// 临时 worker 不在池中，release 已经关闭页面并销毁上下文
+			return
+		}
+		if c.repairWorkers {
+			c.scheduleRepair(worker)
+			return
+		}
+		c.retireWorker(worker)
 	}()
 
-	response, pageBroken, err := c.browser.runPage(ctx, worker.page, url, ro.pageOptions(), func(page *rod.Page, response *proto.NetworkResponseReceived) error {
-		return onPageLoad(page, response)
-	})
-	trace.setResponse(response)
-	if pageBroken || !reuseWorker || !isReusableWorkerPage(worker.page) {
-		state = workerStateBroken
+	page, endRequest, err := c.beginWorkerRequest(worker, url, ro, &trace)
+	defer func() {
+		req.state = endRequest(req.state)
+	}()
+	if err != nil {
+		if errors.Is(err, errResetOverrides) {
+			req.state = workerStateBroken
+		}
+		return err
 	}
+	req.page = page
 
+	if capture, err = startRequestCapture(worker.page, ro); err != nil {
+		req.state = workerStateBroken
+		return err
+	}
+	req.capture = capture
+
+	response, err := run(req)
+	capture.finish()
+	trace.setResponse(response)
+	if !isReusableWorkerPage(worker.page) {
+		req.state = workerStateBroken
+	}
 	return err
 }
 
@@ -449,7 +551,75 @@ func (ro RequestOptions) pageOptions() *PageOptions {
 		waitTimeout:        ro.WaitTimeout,
 		beforeRequest:      ro.BeforeRequest,
 		removeInvisibleDiv: ro.RemoveInvisibleDiv,
-	}
+		waitFor:            ro.WaitFor,
+		waitStrategy:       ro.WaitStrategy,
+		actions:            ro.Actions,
+		autoScroll:         ro.AutoScroll,
+	}
+}
+
+// beginWorkerRequest 切换 worker 的请求级状态并应用请求级覆盖项，返回绑定到本次请求的页面和结束请求的函数；
+// 结束函数需要在归还 worker 之前以当前 worker 状态调用并返回归还时的状态，负责把对话框记录写入 trace、
+// 关闭请求期间遗留的新页面、恢复覆盖项，worker 可复用时再判断是否回收，不回收则按 Config.WorkerReset 重置页面；
+// 恢复或重置失败时返回损坏状态，应用失败但已经恢复时只返回错误，worker 仍可复用
+func (c *Client) beginWorkerRequest(worker *worker, url string, ro RequestOptions, trace *traceSession) (*rod.Page, func(workerState) workerState, error) {
+	worker.requests++
+	endDialogs := worker.dialogs.begin(c.dialogPolicyFor(ro))
+	page, cancelPage := requestPage(worker.page)
+	resetOverrides := func() error { return nil }
+	var err error
+	if worker.page != nil {
+		resetOverrides, err = applyOverrides(worker.page, c.browser.baselineDevice(), url, ro)
+	}
+	return page, func(state workerState) workerState {
+		cancelPage()
+		trace.setDialogs(endDialogs())
+		opened, closed := worker.popups.release()
+		trace.setPopups(opened)
+		c.popupsOpened.Add(int64(opened))
+		c.popupsClosed.Add(int64(closed))
+		if err := resetOverrides(); err != nil {
+			return workerStateBroken
+		}
+		// 损坏的 worker 会被替换，临时上下文中的 worker 会被销毁，都不需要回收或重置
+		if state != workerStateReady || ro.IsolatedContext || worker.page == nil {
+			return state
+		}
+		if c.recycler.shouldRecycle(worker) {
+			return workerStateRecycle
+		}
+		if c.workerReset.Disabled {
+			return state
+		}
+
+		start := time.Now()
+		var origins []string
+		if c.workerReset.ClearStorage {
+			finalURL := ""
+			if info, err := worker.page.Info(); err == nil {
+				finalURL = info.URL
+			}
+			origins = requestOrigins(url, finalURL)
+		}
+		err := resetWorkerPage(worker.page, c.browser.baselineDevice(), c.workerReset, origins)
+		trace.setResetDuration(time.Since(start))
+		if err != nil {
+			return workerStateBroken
+		}
+		return state
+	}, err
+}
+
+// dialogPolicyFor 请求没有设置 DialogPolicy 时使用 Config.DialogPolicy
+func (c *Client) dialogPolicyFor(ro RequestOptions) DialogPolicy {
+	return ro.DialogPolicy.or(c.dialogPolicy)
+}
+
+// pageOptions 请求没有设置 WaitStrategy 时使用 Config.WaitStrategy
+func (c *Client) pageOptions(ro RequestOptions) *PageOptions {
+	po := ro.pageOptions()
+	po.waitStrategy = po.waitStrategy.or(c.waitStrategy)
+	return po
 }
 
 func (c *Client) traceStats() (int, string) {
@@ -7,9 +7,12 @@ import (
 	"flag"
 	"fmt"
 	"io"
+	"net/http"
 	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/LubyRuffy/pageviewer"
@@ -26,6 +29,39 @@ type cliOptions struct {
 	proxy              string
 	noHeadless         bool
 	devTools           bool
+	output             string
+	screenshotFormat   string
+	quality            int
+	fullPage           bool
+	selector           string
+	paperSize          string
+	landscape          bool
+	printBackground    bool
+	pageRanges         string
+	archiveFormat      string
+	warc               string
+	cookiesIn          string
+	cookiesOut         string
+	dedupLinks         bool
+	stripFragments     bool
+	schemaPath         string
+	schema             pageviewer.Schema
+	maxBodySize        int64
+	contentTypes       string
+	waitSelector       string
+	waitText           string
+	waitJS             string
+	waitStrategyName   string
+	autoScroll         bool
+	headers            http.Header
+	userAgent          string
+	deviceName         string
+	device             *pageviewer.DeviceProfile
+	locale             string
+	timezone           string
+	geo                string
+	geolocation        *pageviewer.Geolocation
+	waitStrategy       pageviewer.WaitStrategy
 }
 
 type fetcher interface {
@@ -34,6 +70,15 @@ type fetcher interface {
 	Links(ctx context.Context, url string, opts ...pageviewer.RequestOption) (string, error)
 	ReadabilityArticle(ctx context.Context, url string, opts ...pageviewer.RequestOption) (pageviewer.ReadabilityArticleWithMarkdown, error)
 	RawText(ctx context.Context, url string, opts ...pageviewer.RequestOption) (pageviewer.TextResponse, error)
+	Snapshot(ctx context.Context, url string, so pageviewer.SnapshotOptions, opts ...pageviewer.RequestOption) (pageviewer.Snapshot, error)
+	Screenshot(ctx context.Context, url string, so pageviewer.ScreenshotOptions, opts ...pageviewer.RequestOption) ([]byte, error)
+	PDF(ctx context.Context, url string, po pageviewer.PDFOptions, opts ...pageviewer.RequestOption) ([]byte, error)
+	Archive(ctx context.Context, url string, ao pageviewer.ArchiveOptions, opts ...pageviewer.RequestOption) ([]byte, error)
+	Metadata(ctx context.Context, url string, opts ...pageviewer.RequestOption) (pageviewer.Metadata, error)
+	Extract(ctx context.Context, url string, schema pageviewer.Schema, opts ...pageviewer.RequestOption) (map[string]any, error)
+	RawBytes(ctx context.Context, url string, opts ...pageviewer.RequestOption) (pageviewer.BytesResponse, error)
+	Cookies(ctx context.Context, filter pageviewer.CookieFilter) ([]*http.Cookie, error)
+	SetCookies(ctx context.Context, cookies []*http.Cookie) error
 }
 
 type modeValues []string
@@ -47,6 +92,18 @@ func (m *modeValues) Set(value string) error {
 	return nil
 }
 
+// headerValues 可重复的 --header 参数，格式为 "Name: value"
+type headerValues []string
+
+func (h *headerValues) String() string {
+	return strings.Join(*h, ",")
+}
+
+func (h *headerValues) Set(value string) error {
+	*h = append(*h, value)
+	return nil
+}
+
 type jsonOutputEnvelope struct {
 	Modes   []string       `json:"modes"`
 	URL     string         `json:"url"`
@@ -63,12 +120,26 @@ type rawTextResult struct {
 	StatusCode  int                 `json:"status_code"`
 	FinalURL    string              `json:"final_url"`
 	Header      map[string][]string `json:"header"`
+	Charset     string              `json:"charset"`
+}
+
+type linksResult struct {
+	Content string            `json:"content"`
+	Links   []pageviewer.Link `json:"links"`
+}
+
+type binaryResult struct {
+	Format string `json:"format"`
+	Data   []byte `json:"data"`
 }
 
 var startClient = func(ctx context.Context, cfg pageviewer.Config) (fetcher, error) {
 	return pageviewer.Start(ctx, cfg)
 }
 
+// defaultGeoAccuracy --geo 省略精度时使用的精度，单位为米
+const defaultGeoAccuracy = 100
+
 const usageText = `Usage:
   pageviewer --url <url> [--mode <mode>] [options]
   pageviewer --url <url> --json [--mode <mode>] [--mode <mode>] [options]
@@ -78,18 +149,53 @@ Modes:
   links      Page links text
   article    Readability article markdown / JSON fields
   raw-text   Main document raw text response
+  raw-bytes  Main document raw bytes of any content type; written to --output, base64 with --json
+  extract    Evaluate the --schema extraction rules and print the result as JSON
+  metadata   Title, description, canonical, hreflang, robots, OpenGraph, Twitter, JSON-LD and microdata as JSON
+  screenshot Page screenshot; written to --output, base64 with --json
+  pdf        Print page to PDF; written to --output, base64 with --json
+  archive    Offline page archive (MHTML or single-file HTML); written to --output, base64 with --json
 
 Options:
   --url string                  Target URL; defaults to https:// when scheme omitted
   --mode value                  Output mode; defaults to html, repeatable with --json
   --json                        Render JSON output
   --wait-timeout duration       Page wait timeout, e.g. 15s
+  --wait-strategy string        Page wait strategy: fast|balanced|thorough|raw-document-only
+  --auto-scroll                 Scroll until the page stops growing and load lazy images before extracting
+  --header string               Extra request header "Name: value", can be repeated
+  --user-agent string           Override the User-Agent
+  --device string               Emulate a built-in device, e.g. iphone-15, pixel-8, desktop-1080p
+  --locale string               Override the locale, e.g. de-DE
+  --timezone string             Override the timezone, e.g. Asia/Shanghai
+  --geo string                  Override the geolocation as "lat,lon[,accuracy]"
+  --wait-selector string        Wait until the CSS selector is visible before extracting
+  --wait-text string            Wait until the page text contains the string before extracting
+  --wait-js string              Wait until the JS predicate returns true before extracting
   --trace-id string             Trace ID for debugging
   --remove-invisible-div        Remove invisible div elements
   --acquire-timeout duration    Worker acquire timeout, e.g. 5s
   --proxy string                Browser proxy
   --no-headless                 Show browser window
   --devtools                    Open DevTools
+  --output string               Output file for binary modes such as screenshot
+  --screenshot-format string    Screenshot format: png|jpeg|webp (default png)
+  --quality int                 Screenshot quality 0-100 for jpeg/webp
+  --full-page                   Capture the full scrollable page
+  --selector string             Capture only the first element matching the CSS selector
+  --paper-size string           PDF paper size: letter|legal|tabloid|a3|a4|a5 (default letter)
+  --landscape                   Print PDF in landscape orientation
+  --print-background            Print background graphics in PDF
+  --page-ranges string          PDF page ranges, e.g. 1-5,8
+  --archive-format string       Archive format: mhtml|html (default mhtml)
+  --dedup-links                 Deduplicate links by URL in --json links output
+  --strip-fragments             Strip #fragment from URLs in --json links output
+  --schema string               Extraction schema JSON file; defaults --mode to extract
+  --warc string                 Write all network requests/responses to a gzip-compressed WARC file
+  --cookies-in string           Load cookies before the request from a cookies.txt or .json file
+  --cookies-out string          Save browser cookies after the request to a cookies.txt or .json file
+  --max-body-size int           Maximum raw-bytes response size in bytes (default 64 MiB)
+  --content-types string        Comma-separated raw-bytes content type allowlist, e.g. application/pdf,image/*
   -h, --help                    Show this help
 `
 
@@ -99,16 +205,46 @@ func parseFlags(args []string) (cliOptions, error) {
 
 	var opts cliOptions
 	var modes modeValues
+	var headers headerValues
 	fs.StringVar(&opts.url, "url", "", "target url")
-	fs.Var(&modes, "mode", "output mode: html|links|article|raw-text")
+	fs.Var(&modes, "mode", "output mode: html|links|article|raw-text|raw-bytes|metadata|extract|screenshot|pdf|archive")
 	fs.BoolVar(&opts.jsonOutput, "json", false, "render JSON output")
 	fs.DurationVar(&opts.waitTimeout, "wait-timeout", 0, "page wait timeout")
+	fs.StringVar(&opts.waitStrategyName, "wait-strategy", "", "page wait strategy: fast|balanced|thorough|raw-document-only")
+	fs.BoolVar(&opts.autoScroll, "auto-scroll", false, "scroll until the page stops growing before extracting")
+	fs.Var(&headers, "header", `extra request header "Name: value", can be repeated`)
+	fs.StringVar(&opts.userAgent, "user-agent", "", "override the User-Agent")
+	fs.StringVar(&opts.deviceName, "device", "", "emulate a built-in device, e.g. iphone-15")
+	fs.StringVar(&opts.locale, "locale", "", "override the locale, e.g. de-DE")
+	fs.StringVar(&opts.timezone, "timezone", "", "override the timezone, e.g. Asia/Shanghai")
+	fs.StringVar(&opts.geo, "geo", "", `override the geolocation as "lat,lon[,accuracy]"`)
+	fs.StringVar(&opts.waitSelector, "wait-selector", "", "wait until the CSS selector is visible")
+	fs.StringVar(&opts.waitText, "wait-text", "", "wait until the page text contains the string")
+	fs.StringVar(&opts.waitJS, "wait-js", "", "wait until the JS predicate returns true")
 	fs.StringVar(&opts.traceID, "trace-id", "", "trace id")
 	fs.BoolVar(&opts.removeInvisibleDiv, "remove-invisible-div", false, "remove invisible div")
 	fs.DurationVar(&opts.acquireTimeout, "acquire-timeout", 0, "worker acquire timeout")
 	fs.StringVar(&opts.proxy, "proxy", "", "browser proxy")
 	fs.BoolVar(&opts.noHeadless, "no-headless", false, "show browser window")
 	fs.BoolVar(&opts.devTools, "devtools", false, "open devtools")
+	fs.StringVar(&opts.output, "output", "", "output file for binary modes")
+	fs.StringVar(&opts.screenshotFormat, "screenshot-format", "", "screenshot format: png|jpeg|webp")
+	fs.IntVar(&opts.quality, "quality", 0, "screenshot quality 0-100")
+	fs.BoolVar(&opts.fullPage, "full-page", false, "capture the full scrollable page")
+	fs.StringVar(&opts.selector, "selector", "", "capture only the matching element")
+	fs.StringVar(&opts.paperSize, "paper-size", "", "pdf paper size")
+	fs.BoolVar(&opts.landscape, "landscape", false, "print pdf in landscape orientation")
+	fs.BoolVar(&opts.printBackground, "print-background", false, "print background graphics in pdf")
+	fs.StringVar(&opts.pageRanges, "page-ranges", "", "pdf page ranges")
+	fs.StringVar(&opts.archiveFormat, "archive-format", "", "archive format: mhtml|html")
+	fs.BoolVar(&opts.dedupLinks, "dedup-links", false, "deduplicate links by URL in JSON links output")
+	fs.BoolVar(&opts.stripFragments, "strip-fragments", false, "strip #fragment from URLs in JSON links output")
+	fs.StringVar(&opts.schemaPath, "schema", "", "extraction schema JSON file")
+	fs.StringVar(&opts.warc, "warc", "", "write all network traffic to a gzip-compressed WARC file")
+	fs.StringVar(&opts.cookiesIn, "cookies-in", "", "load cookies from a cookies.txt or .json file")
+	fs.StringVar(&opts.cookiesOut, "cookies-out", "", "save cookies to a cookies.txt or .json file")
+	fs.Int64Var(&opts.maxBodySize, "max-body-size", 0, "maximum raw-bytes response size in bytes")
+	fs.StringVar(&opts.contentTypes, "content-types", "", "comma-separated raw-bytes content type allowlist")
 
 	if err := fs.Parse(args); err != nil {
 		return cliOptions{}, err
@@ -124,6 +260,9 @@ func parseFlags(args []string) (cliOptions, error) {
 	opts.modes = append(opts.modes, modes...)
 	if len(opts.modes) == 0 {
 		opts.modes = []string{"html"}
+		if opts.schemaPath != "" {
+			opts.modes = []string{"extract"}
+		}
 	}
 	seenModes := make(map[string]struct{}, len(opts.modes))
 	for _, mode := range opts.modes {
@@ -138,6 +277,78 @@ func parseFlags(args []string) (cliOptions, error) {
 	if !opts.jsonOutput && len(opts.modes) > 1 {
 		return cliOptions{}, errors.New("multiple --mode values require --json")
 	}
+	if !opts.jsonOutput && isBinaryMode(opts.modes[0]) && opts.output == "" {
+		return cliOptions{}, fmt.Errorf("--output is required for --mode %s", opts.modes[0])
+	}
+	if opts.schemaPath != "" {
+		data, err := os.ReadFile(opts.schemaPath)
+		if err != nil {
+			return cliOptions{}, fmt.Errorf("invalid --schema: %w", err)
+		}
+		if opts.schema, err = pageviewer.ParseSchema(data); err != nil {
+			return cliOptions{}, fmt.Errorf("invalid --schema: %w", err)
+		}
+	} else if _, ok := seenModes["extract"]; ok {
+		return cliOptions{}, errors.New("--schema is required for --mode extract")
+	}
+	switch opts.screenshotFormat {
+	case "", "png", "jpeg", "webp":
+	default:
+		return cliOptions{}, fmt.Errorf("invalid --screenshot-format: %s", opts.screenshotFormat)
+	}
+	if opts.quality < 0 || opts.quality > 100 {
+		return cliOptions{}, fmt.Errorf("invalid --quality: %d", opts.quality)
+	}
+	if opts.quality > 0 && (opts.screenshotFormat == "" || opts.screenshotFormat == "png") {
+		return cliOptions{}, errors.New("--quality requires --screenshot-format jpeg or webp")
+	}
+	if opts.fullPage && opts.selector != "" {
+		return cliOptions{}, errors.New("--full-page cannot be combined with --selector")
+	}
+	switch strings.ToLower(opts.paperSize) {
+	case "", "letter", "legal", "tabloid", "a3", "a4", "a5":
+	default:
+		return cliOptions{}, fmt.Errorf("invalid --paper-size: %s", opts.paperSize)
+	}
+	switch opts.archiveFormat {
+	case "", "mhtml", "html":
+	default:
+		return cliOptions{}, fmt.Errorf("invalid --archive-format: %s", opts.archiveFormat)
+	}
+	if opts.maxBodySize < 0 {
+		return cliOptions{}, fmt.Errorf("invalid --max-body-size: %d", opts.maxBodySize)
+	}
+	for _, header := range headers {
+		name, value, ok := strings.Cut(header, ":")
+		if !ok || strings.TrimSpace(name) == "" {
+			return cliOptions{}, fmt.Errorf("invalid --header: %s", header)
+		}
+		if opts.headers == nil {
+			opts.headers = make(http.Header)
+		}
+		opts.headers.Add(strings.TrimSpace(name), strings.TrimSpace(value))
+	}
+	if opts.deviceName != "" {
+		device, err := pageviewer.DeviceByName(opts.deviceName)
+		if err != nil {
+			return cliOptions{}, fmt.Errorf("invalid --device: %s", opts.deviceName)
+		}
+		opts.device = &device
+	}
+	if opts.geo != "" {
+		geolocation, err := parseGeolocation(opts.geo)
+		if err != nil {
+			return cliOptions{}, fmt.Errorf("invalid --geo: %s", opts.geo)
+		}
+		opts.geolocation = &geolocation
+	}
+	if opts.waitStrategyName != "" {
+		strategy, err := pageviewer.WaitStrategyByName(opts.waitStrategyName)
+		if err != nil {
+			return cliOptions{}, fmt.Errorf("invalid --wait-strategy: %s", opts.waitStrategyName)
+		}
+		opts.waitStrategy = strategy
+	}
 	return opts, nil
 }
 
@@ -170,22 +381,102 @@ func normalizeURL(raw string) (string, error) {
 
 func validateMode(mode string) error {
 	switch mode {
-	case "html", "links", "article", "raw-text":
+	case "html", "links", "article", "raw-text", "raw-bytes", "metadata", "extract", "screenshot", "pdf", "archive":
 		return nil
 	default:
 		return fmt.Errorf("invalid --mode: %s", mode)
 	}
 }
 
+func isBinaryMode(mode string) bool {
+	switch mode {
+	case "raw-bytes", "screenshot", "pdf", "archive":
+		return true
+	default:
+		return false
+	}
+}
+
+func screenshotOptions(opts cliOptions) pageviewer.ScreenshotOptions {
+	return pageviewer.ScreenshotOptions{
+		Format:   pageviewer.ScreenshotFormat(opts.screenshotFormat),
+		Quality:  opts.quality,
+		FullPage: opts.fullPage,
+		Selector: opts.selector,
+	}
+}
+
+func pdfOptions(opts cliOptions) pageviewer.PDFOptions {
+	return pageviewer.PDFOptions{
+		PaperSize:       pageviewer.PDFPaperSize(opts.paperSize),
+		Landscape:       opts.landscape,
+		PrintBackground: opts.printBackground,
+		PageRanges:      opts.pageRanges,
+	}
+}
+
+func linkListOptions(opts cliOptions) pageviewer.LinkListOptions {
+	return pageviewer.LinkListOptions{Dedup: opts.dedupLinks, StripFragment: opts.stripFragments}
+}
+
+// parseGeolocation 解析 "lat,lon[,accuracy]"，省略精度时使用 defaultGeoAccuracy 米
+func parseGeolocation(value string) (pageviewer.Geolocation, error) {
+	parts := strings.Split(value, ",")
+	if len(parts) < 2 || len(parts) > 3 {
+		return pageviewer.Geolocation{}, errors.New("expected lat,lon[,accuracy]")
+	}
+	numbers := make([]float64, 3)
+	numbers[2] = defaultGeoAccuracy
+	for i, part := range parts {
+		n, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return pageviewer.Geolocation{}, err
+		}
+		numbers[i] = n
+	}
+	geo := pageviewer.Geolocation{Latitude: numbers[0], Longitude: numbers[1], Accuracy: numbers[2]}
+	if geo.Latitude < -90 || geo.Latitude > 90 || geo.Longitude < -180 || geo.Longitude > 180 || geo.Accuracy < 0 {
+		return pageviewer.Geolocation{}, errors.New("out of range")
+	}
+	return geo, nil
+}
+
+// waitConditions 把 --wait-* 参数转换为等待条件，多个参数需要同时满足
+func waitConditions(opts cliOptions) []pageviewer.WaitCondition {
+	var conditions []pageviewer.WaitCondition
+	if opts.waitSelector != "" {
+		conditions = append(conditions, pageviewer.WaitVisible(opts.waitSelector))
+	}
+	if opts.waitText != "" {
+		conditions = append(conditions, pageviewer.WaitText(opts.waitText))
+	}
+	if opts.waitJS != "" {
+		conditions = append(conditions, pageviewer.WaitJS(opts.waitJS))
+	}
+	return conditions
+}
+
+// splitList 拆分逗号分隔的参数，忽略空项
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func archiveOptions(opts cliOptions) pageviewer.ArchiveOptions {
+	return pageviewer.ArchiveOptions{Format: pageviewer.ArchiveFormat(opts.archiveFormat)}
+}
+
 func buildConfig(opts cliOptions) (pageviewer.Config, []pageviewer.RequestOption) {
 	cfg := pageviewer.DefaultConfig()
 	cfg.Proxy = opts.proxy
 	cfg.NoHeadless = opts.noHeadless
 	cfg.DevTools = opts.devTools
-	if opts.jsonOutput && len(opts.modes) > 1 {
-		cfg.PoolSize = len(opts.modes)
-		cfg.Warmup = len(opts.modes)
-	}
+	cfg.WaitStrategy = opts.waitStrategy
 
 	reqOpts := make([]pageviewer.RequestOption, 0, 4)
 	if opts.waitTimeout > 0 {
@@ -200,6 +491,36 @@ func buildConfig(opts cliOptions) (pageviewer.Config, []pageviewer.RequestOption
 	if opts.acquireTimeout > 0 {
 		reqOpts = append(reqOpts, pageviewer.WithAcquireTimeout(opts.acquireTimeout))
 	}
+	if len(opts.headers) > 0 {
+		reqOpts = append(reqOpts, pageviewer.WithHeaders(opts.headers))
+	}
+	if opts.userAgent != "" {
+		reqOpts = append(reqOpts, pageviewer.WithUserAgent(opts.userAgent))
+	}
+	if opts.device != nil {
+		reqOpts = append(reqOpts, pageviewer.WithDevice(*opts.device))
+	}
+	if opts.locale != "" {
+		reqOpts = append(reqOpts, pageviewer.WithLocale(opts.locale))
+	}
+	if opts.timezone != "" {
+		reqOpts = append(reqOpts, pageviewer.WithTimezone(opts.timezone))
+	}
+	if geo := opts.geolocation; geo != nil {
+		reqOpts = append(reqOpts, pageviewer.WithGeolocation(geo.Latitude, geo.Longitude, geo.Accuracy))
+	}
+	if opts.autoScroll {
+		reqOpts = append(reqOpts, pageviewer.WithAutoScroll(pageviewer.AutoScrollOptions{}))
+	}
+	if conditions := waitConditions(opts); len(conditions) > 0 {
+		reqOpts = append(reqOpts, pageviewer.WithWaitFor(conditions...))
+	}
+	if opts.maxBodySize > 0 {
+		reqOpts = append(reqOpts, pageviewer.WithMaxBodySize(opts.maxBodySize))
+	}
+	if types := splitList(opts.contentTypes); len(types) > 0 {
+		reqOpts = append(reqOpts, pageviewer.WithAllowedContentTypes(types...))
+	}
 
 	return cfg, reqOpts
 }
@@ -216,6 +537,26 @@ func runCLI(ctx context.Context, args []string, stdout io.Writer, stderr io.Writ
 	}
 
 	cfg, reqOpts := buildConfig(opts)
+	if opts.warc != "" {
+		warcFile, err := createWARCFile(opts.warc)
+		if err != nil {
+			return writeError(stderr, err)
+		}
+		defer func() {
+			if closeErr := warcFile.Close(); closeErr != nil && exitCode == 0 {
+				exitCode = writeError(stderr, closeErr)
+			}
+		}()
+		reqOpts = append(reqOpts, pageviewer.WithWARC(warcFile))
+	}
+
+	var cookies []*http.Cookie
+	if opts.cookiesIn != "" {
+		if cookies, err = readCookiesFile(opts.cookiesIn); err != nil {
+			return writeError(stderr, err)
+		}
+	}
+
 	client, err := startClient(ctx, cfg)
 	if err != nil {
 		return writeFetchError(stderr, err, opts.traceID)
@@ -227,11 +568,70 @@ func runCLI(ctx context.Context, args []string, stdout io.Writer, stderr io.Writ
 		}
 	}()
 
+	if len(cookies) > 0 {
+		if err := client.SetCookies(ctx, cookies); err != nil {
+			return writeError(stderr, err)
+		}
+	}
+
 	if opts.jsonOutput {
-		return runJSONModes(ctx, client, opts, reqOpts, stdout, stderr)
+		exitCode = runJSONModes(ctx, client, opts, reqOpts, stdout, stderr)
+	} else {
+		exitCode = runTextMode(ctx, client, opts, reqOpts, stdout, stderr)
+	}
+
+	if opts.cookiesOut != "" && exitCode == 0 {
+		cookies, err := client.Cookies(ctx, pageviewer.CookieFilter{})
+		if err != nil {
+			return writeError(stderr, err)
+		}
+		if err := writeCookiesFile(opts.cookiesOut, cookies); err != nil {
+			return writeError(stderr, err)
+		}
 	}
+	return exitCode
+}
+
+// readCookiesFile 按扩展名读取 cookie 文件，.json 为 JSON 数组格式，其他为 Netscape cookies.txt 格式
+func readCookiesFile(path string) ([]*http.Cookie, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
 
-	return runTextMode(ctx, client, opts, reqOpts, stdout, stderr)
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		return pageviewer.ReadJSONCookies(file)
+	}
+	return pageviewer.ReadNetscapeCookies(file)
+}
+
+// writeCookiesFile 按扩展名写出 cookie 文件，格式与 readCookiesFile 一致
+func writeCookiesFile(path string, cookies []*http.Cookie) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		err = pageviewer.WriteJSONCookies(file, cookies)
+	} else {
+		err = pageviewer.WriteNetscapeCookies(file, cookies)
+	}
+	return errors.Join(err, file.Close())
+}
+
+// createWARCFile 创建 WARC 文件并写入描述文件本身的 warcinfo 记录
+func createWARCFile(path string) (*os.File, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := pageviewer.NewWARCWriter(file, true).WriteWarcinfo(filepath.Base(path)); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	return file, nil
 }
 
 func runTextMode(ctx context.Context, client fetcher, opts cliOptions, reqOpts []pageviewer.RequestOption, stdout io.Writer, stderr io.Writer) int {
@@ -264,38 +664,36 @@ func runTextMode(ctx context.Context, client fetcher, opts cliOptions, reqOpts [
 		}
 		_, _ = fmt.Fprint(stdout, text.Body)
 		return 0
+	case "metadata":
+		metadata, err := client.Metadata(ctx, opts.url, reqOpts...)
+		if err != nil {
+			return writeFetchError(stderr, err, opts.traceID)
+		}
+		return writeJSON(stdout, stderr, metadata)
+	case "extract":
+		result, err := client.Extract(ctx, opts.url, opts.schema, reqOpts...)
+		if err != nil {
+			return writeFetchError(stderr, err, opts.traceID)
+		}
+		return writeJSON(stdout, stderr, result)
+	case "raw-bytes", "screenshot", "pdf", "archive":
+		result, err := fetchBinaryResult(ctx, client, opts, opts.modes[0], reqOpts)
+		if err != nil {
+			return writeFetchError(stderr, err, opts.traceID)
+		}
+		if err := os.WriteFile(opts.output, result.Data, 0o644); err != nil {
+			return writeError(stderr, err)
+		}
+		return 0
 	default:
 		return writeFetchError(stderr, fmt.Errorf("invalid --mode: %s", opts.modes[0]), opts.traceID)
 	}
 }
 
 func runJSONModes(ctx context.Context, client fetcher, opts cliOptions, reqOpts []pageviewer.RequestOption, stdout io.Writer, stderr io.Writer) int {
-	type modeRun struct {
-		mode   string
-		result any
-		err    error
-	}
-
-	resultsCh := make(chan modeRun, len(opts.modes))
-	var wg sync.WaitGroup
-
-	for _, mode := range opts.modes {
-		wg.Add(1)
-		go func(mode string) {
-			defer wg.Done()
-			result, err := fetchModeResult(ctx, client, opts.url, mode, reqOpts)
-			resultsCh <- modeRun{mode: mode, result: result, err: err}
-		}(mode)
-	}
-	wg.Wait()
-	close(resultsCh)
-
-	results := make(map[string]any, len(opts.modes))
-	for result := range resultsCh {
-		if result.err != nil {
-			return writeFetchError(stderr, result.err, opts.traceID)
-		}
-		results[result.mode] = result.result
+	results, err := fetchModeResults(ctx, client, opts, reqOpts)
+	if err != nil {
+		return writeFetchError(stderr, err, opts.traceID)
 	}
 
 	return writeJSON(stdout, stderr, jsonOutputEnvelope{
@@ -309,7 +707,8 @@ func writeUsage(stdout io.Writer) {
 	_, _ = io.WriteString(stdout, usageText)
 }
 
-func fetchModeResult(ctx context.Context, client fetcher, url, mode string, reqOpts []pageviewer.RequestOption) (any, error) {
+func fetchModeResult(ctx context.Context, client fetcher, opts cliOptions, mode string, reqOpts []pageviewer.RequestOption) (any, error) {
+	url := opts.url
 	switch mode {
 	case "html":
 		content, err := client.HTML(ctx, url, reqOpts...)
@@ -318,11 +717,11 @@ func fetchModeResult(ctx context.Context, client fetcher, url, mode string, reqO
 		}
 		return textResult{Content: content}, nil
 	case "links":
-		content, err := client.Links(ctx, url, reqOpts...)
+		snapshot, err := client.Snapshot(ctx, url, pageviewer.SnapshotOptions{Links: true, LinkOptions: linkListOptions(opts)}, reqOpts...)
 		if err != nil {
 			return nil, err
 		}
-		return textResult{Content: content}, nil
+		return newLinksResult(snapshot), nil
 	case "article":
 		article, err := client.ReadabilityArticle(ctx, url, reqOpts...)
 		if err != nil {
@@ -334,18 +733,145 @@ func fetchModeResult(ctx context.Context, client fetcher, url, mode string, reqO
 		if err != nil {
 			return nil, err
 		}
-		return rawTextResult{
-			Body:        text.Body,
-			ContentType: text.ContentType,
-			StatusCode:  text.StatusCode,
-			FinalURL:    text.FinalURL,
-			Header:      text.Header,
-		}, nil
+		return newRawTextResult(text), nil
+	case "metadata":
+		metadata, err := client.Metadata(ctx, url, reqOpts...)
+		if err != nil {
+			return nil, err
+		}
+		return metadata, nil
+	case "extract":
+		result, err := client.Extract(ctx, url, opts.schema, reqOpts...)
+		if err != nil {
+			return nil, err
+		}
+		return result, nil
+	case "raw-bytes", "screenshot", "pdf", "archive":
+		return fetchBinaryResult(ctx, client, opts, mode, reqOpts)
 	default:
 		return nil, fmt.Errorf("invalid --mode: %s", mode)
 	}
 }
 
+func fetchBinaryResult(ctx context.Context, client fetcher, opts cliOptions, mode string, reqOpts []pageviewer.RequestOption) (binaryResult, error) {
+	var result binaryResult
+	var err error
+	switch mode {
+	case "raw-bytes":
+		var resp pageviewer.BytesResponse
+		resp, err = client.RawBytes(ctx, opts.url, reqOpts...)
+		result.Format = resp.ContentType
+		result.Data = resp.Body
+	case "screenshot":
+		so := screenshotOptions(opts)
+		result.Format = string(so.Format)
+		if result.Format == "" {
+			result.Format = string(pageviewer.ScreenshotFormatPNG)
+		}
+		result.Data, err = client.Screenshot(ctx, opts.url, so, reqOpts...)
+	case "pdf":
+		result.Format = "pdf"
+		result.Data, err = client.PDF(ctx, opts.url, pdfOptions(opts), reqOpts...)
+	case "archive":
+		ao := archiveOptions(opts)
+		result.Format = string(ao.Format)
+		if result.Format == "" {
+			result.Format = string(pageviewer.ArchiveFormatMHTML)
+		}
+		result.Data, err = client.Archive(ctx, opts.url, ao, reqOpts...)
+	default:
+		err = fmt.Errorf("invalid --mode: %s", mode)
+	}
+	if err != nil {
+		return binaryResult{}, err
+	}
+	return result, nil
+}
+
+// fetchModeResults 单个 mode 直接走对应接口，多个 mode 时合并为一次 Snapshot 页面加载
+func fetchModeResults(ctx context.Context, client fetcher, opts cliOptions, reqOpts []pageviewer.RequestOption) (map[string]any, error) {
+	modes := opts.modes
+	results := make(map[string]any, len(modes))
+	if len(modes) == 1 {
+		result, err := fetchModeResult(ctx, client, opts, modes[0], reqOpts)
+		if err != nil {
+			return nil, err
+		}
+		results[modes[0]] = result
+		return results, nil
+	}
+
+	so := pageviewer.SnapshotOptions{LinkOptions: linkListOptions(opts)}
+	var others []string
+	for _, mode := range modes {
+		switch mode {
+		case "html":
+			so.HTML = true
+		case "links":
+			so.Links = true
+		case "article":
+			so.Article = true
+		case "raw-text":
+			so.RawText = true
+		case "metadata":
+			so.Metadata = true
+		default:
+			others = append(others, mode)
+		}
+	}
+
+	if len(others) < len(modes) {
+		snapshot, err := client.Snapshot(ctx, opts.url, so, reqOpts...)
+		if err != nil {
+			return nil, err
+		}
+		if so.HTML {
+			results["html"] = textResult{Content: snapshot.HTML}
+		}
+		if so.Links {
+			results["links"] = newLinksResult(snapshot)
+		}
+		if so.Article {
+			results["article"] = snapshot.Article
+		}
+		if so.RawText {
+			results["raw-text"] = newRawTextResult(snapshot.Raw)
+		}
+		if so.Metadata {
+			results["metadata"] = snapshot.Metadata
+		}
+	}
+
+	for _, mode := range others {
+		result, err := fetchModeResult(ctx, client, opts, mode, reqOpts)
+		if err != nil {
+			return nil, err
+		}
+		results[mode] = result
+	}
+
+	return results, nil
+}
+
+func newLinksResult(snapshot pageviewer.Snapshot) linksResult {
+	links := snapshot.LinkList
+	if links == nil {
+		links = []pageviewer.Link{}
+	}
+	return linksResult{Content: snapshot.Links, Links: links}
+}
+
+func newRawTextResult(text pageviewer.TextResponse) rawTextResult {
+	return rawTextResult{
+		Body:        text.Body,
+		ContentType: text.ContentType,
+		StatusCode:  text.StatusCode,
+		FinalURL:    text.FinalURL,
+		Header:      text.Header,
+		Charset:     text.Charset,
+	}
+}
+
 func writeError(stderr io.Writer, err error) int {
 	if err == nil {
 		return 0
@@ -2,10 +2,14 @@ package main
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
 	"encoding/json"
 	"errors"
+	"io"
 	"net/http"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -48,7 +52,7 @@ func TestParseFlagsRejectsInvalidURL(t *testing.T) {
 }
 
 func TestParseFlagsRejectsInvalidMode(t *testing.T) {
-	_, err := parseFlags([]string{"--url", "https://example.com", "--mode", "pdf"})
+	_, err := parseFlags([]string{"--url", "https://example.com", "--mode", "unknown"})
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "invalid --mode")
 }
@@ -111,6 +115,104 @@ func TestParseFlagsParsesCommonOptions(t *testing.T) {
 	assert.True(t, opts.devTools)
 }
 
+func TestParseFlagsScreenshotRequiresOutputWithoutJSON(t *testing.T) {
+	_, err := parseFlags([]string{"--url", "https://example.com", "--mode", "screenshot"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--output is required for --mode screenshot")
+
+	opts, err := parseFlags([]string{"--url", "https://example.com", "--mode", "screenshot", "--json"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"screenshot"}, opts.modes)
+}
+
+func TestParseFlagsParsesScreenshotOptions(t *testing.T) {
+	opts, err := parseFlags([]string{
+		"--url", "https://example.com",
+		"--mode", "screenshot",
+		"--output", "page.jpeg",
+		"--screenshot-format", "jpeg",
+		"--quality", "80",
+		"--selector", "#app",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, pageviewer.ScreenshotOptions{
+		Format:   pageviewer.ScreenshotFormatJPEG,
+		Quality:  80,
+		Selector: "#app",
+	}, screenshotOptions(opts))
+	assert.Equal(t, "page.jpeg", opts.output)
+}
+
+func TestParseFlagsRejectsInvalidScreenshotFormat(t *testing.T) {
+	_, err := parseFlags([]string{"--url", "https://example.com", "--mode", "screenshot", "--json", "--screenshot-format", "gif"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid --screenshot-format: gif")
+}
+
+func TestParseFlagsRejectsConflictingScreenshotOptions(t *testing.T) {
+	_, err := parseFlags([]string{"--url", "https://example.com", "--mode", "screenshot", "--output", "page.png", "--quality", "80"})
+	assert.ErrorContains(t, err, "--quality requires --screenshot-format jpeg or webp")
+
+	_, err = parseFlags([]string{"--url", "https://example.com", "--mode", "screenshot", "--output", "page.png", "--full-page", "--selector", "#app"})
+	assert.ErrorContains(t, err, "--full-page cannot be combined with --selector")
+}
+
+func TestParseFlagsParsesPDFOptions(t *testing.T) {
+	opts, err := parseFlags([]string{
+		"--url", "https://example.com",
+		"--mode", "pdf",
+		"--output", "page.pdf",
+		"--paper-size", "a4",
+		"--landscape",
+		"--print-background",
+		"--page-ranges", "1-2",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, pageviewer.PDFOptions{
+		PaperSize:       pageviewer.PDFPaperA4,
+		Landscape:       true,
+		PrintBackground: true,
+		PageRanges:      "1-2",
+	}, pdfOptions(opts))
+
+	_, err = parseFlags([]string{"--url", "https://example.com", "--mode", "pdf"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--output is required for --mode pdf")
+
+	_, err = parseFlags([]string{"--url", "https://example.com", "--mode", "pdf", "--json", "--paper-size", "b5"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid --paper-size: b5")
+}
+
+func TestParseFlagsParsesArchiveFormat(t *testing.T) {
+	opts, err := parseFlags([]string{"--url", "https://example.com", "--mode", "archive", "--output", "page.html", "--archive-format", "html"})
+	require.NoError(t, err)
+	assert.Equal(t, pageviewer.ArchiveOptions{Format: pageviewer.ArchiveFormatSingleFile}, archiveOptions(opts))
+
+	_, err = parseFlags([]string{"--url", "https://example.com", "--mode", "archive", "--json", "--archive-format", "zip"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid --archive-format: zip")
+}
+
+func TestParseFlagsLoadsSchemaAndDefaultsToExtract(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schema.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"fields": [{"name": "title", "selector": "h1"}]}`), 0o644))
+
+	opts, err := parseFlags([]string{"--url", "https://example.com", "--schema", path})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"extract"}, opts.modes)
+	assert.Equal(t, pageviewer.Schema{Fields: []pageviewer.SchemaField{{Name: "title", Selector: "h1"}}}, opts.schema)
+
+	_, err = parseFlags([]string{"--url", "https://example.com", "--mode", "extract"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--schema is required")
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"fields": [{"name": ""}]}`), 0o644))
+	_, err = parseFlags([]string{"--url", "https://example.com", "--schema", path})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid --schema")
+}
+
 func TestBuildConfigMapsBrowserAndRequestOptions(t *testing.T) {
 	opts := cliOptions{
 		url:                "https://example.com",
@@ -131,7 +233,115 @@ func TestBuildConfigMapsBrowserAndRequestOptions(t *testing.T) {
 	assert.Len(t, reqOpts, 4)
 }
 
-func TestBuildConfigExpandsPoolForJSONMultiMode(t *testing.T) {
+func TestBuildConfigMapsWaitConditions(t *testing.T) {
+	opts, err := parseFlags([]string{"--url", "https://example.com", "--wait-selector", "#app", "--wait-js", "window.ready"})
+	require.NoError(t, err)
+
+	_, reqOpts := buildConfig(opts)
+	waitFor := pageviewer.NewRequestOptions(reqOpts...).WaitFor
+	require.Len(t, waitFor, 2)
+	assert.Equal(t, `visible("#app")`, waitFor[0].String())
+	assert.Equal(t, `js("window.ready")`, waitFor[1].String())
+}
+
+func TestParseFlagsMapsWaitStrategy(t *testing.T) {
+	opts, err := parseFlags([]string{"--url", "https://example.com", "--wait-strategy", "fast"})
+	require.NoError(t, err)
+
+	cfg, _ := buildConfig(opts)
+	assert.Equal(t, pageviewer.WaitStrategyFast(), cfg.WaitStrategy)
+
+	_, err = parseFlags([]string{"--url", "https://example.com", "--wait-strategy", "slow"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid --wait-strategy")
+}
+
+func TestBuildConfigMapsAutoScroll(t *testing.T) {
+	opts, err := parseFlags([]string{"--url", "https://example.com", "--auto-scroll"})
+	require.NoError(t, err)
+
+	_, reqOpts := buildConfig(opts)
+	assert.NotNil(t, pageviewer.NewRequestOptions(reqOpts...).AutoScroll)
+}
+
+func TestParseFlagsMapsHeadersAndUserAgent(t *testing.T) {
+	opts, err := parseFlags([]string{"--url", "https://example.com", "--header", "X-Token: abc", "--header", "X-Token:def", "--user-agent", "pageviewer-test"})
+	require.NoError(t, err)
+
+	_, reqOpts := buildConfig(opts)
+	ro := pageviewer.NewRequestOptions(reqOpts...)
+	assert.Equal(t, []string{"abc", "def"}, ro.Headers.Values("X-Token"))
+	assert.Equal(t, "pageviewer-test", ro.UserAgent)
+
+	_, err = parseFlags([]string{"--url", "https://example.com", "--header", "no-colon"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid --header")
+}
+
+func TestParseFlagsMapsDevice(t *testing.T) {
+	opts, err := parseFlags([]string{"--url", "https://example.com", "--device", "iphone-15"})
+	require.NoError(t, err)
+
+	_, reqOpts := buildConfig(opts)
+	ro := pageviewer.NewRequestOptions(reqOpts...)
+	require.NotNil(t, ro.Device)
+	assert.Equal(t, pageviewer.DeviceIPhone15, *ro.Device)
+
+	_, err = parseFlags([]string{"--url", "https://example.com", "--device", "nokia-3310"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid --device")
+}
+
+func TestParseFlagsMapsLocaleTimezoneAndGeo(t *testing.T) {
+	opts, err := parseFlags([]string{"--url", "https://example.com", "--locale", "de-DE", "--timezone", "Europe/Berlin", "--geo", "52.52, 13.405"})
+	require.NoError(t, err)
+
+	_, reqOpts := buildConfig(opts)
+	ro := pageviewer.NewRequestOptions(reqOpts...)
+	assert.Equal(t, "de-DE", ro.Locale)
+	assert.Equal(t, "Europe/Berlin", ro.Timezone)
+	assert.Equal(t, &pageviewer.Geolocation{Latitude: 52.52, Longitude: 13.405, Accuracy: defaultGeoAccuracy}, ro.Geolocation)
+
+	for _, geo := range []string{"52.52", "north,east", "91,0", "0,0,-1", "1,2,3,4"} {
+		_, err = parseFlags([]string{"--url", "https://example.com", "--geo", geo})
+		require.Error(t, err, geo)
+		assert.Contains(t, err.Error(), "invalid --geo")
+	}
+}
+
+func TestRunCLIRoundTripsCookieFiles(t *testing.T) {
+	dir := t.TempDir()
+	cookiesIn := filepath.Join(dir, "cookies.txt")
+	cookiesOut := filepath.Join(dir, "cookies.json")
+	require.NoError(t, os.WriteFile(cookiesIn, []byte("# Netscape HTTP Cookie File\n.example.com\tTRUE\t/\tFALSE\t0\tsid\tabc\n"), 0o600))
+
+	fake := &fakeFetcher{
+		htmlFn: func(ctx context.Context, url string, opts ...pageviewer.RequestOption) (string, error) {
+			return "<html></html>", nil
+		},
+	}
+	original := startClient
+	startClient = func(ctx context.Context, cfg pageviewer.Config) (fetcher, error) {
+		return fake, nil
+	}
+	t.Cleanup(func() { startClient = original })
+
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	code := runCLI(context.Background(), []string{"--url", "https://example.com", "--cookies-in", cookiesIn, "--cookies-out", cookiesOut}, &stdout, &stderr)
+	require.Equal(t, 0, code, stderr.String())
+	require.Len(t, fake.cookies, 1)
+	assert.Equal(t, &http.Cookie{Name: "sid", Value: "abc", Domain: ".example.com", Path: "/"}, fake.cookies[0])
+
+	file, err := os.Open(cookiesOut)
+	require.NoError(t, err)
+	defer file.Close()
+	saved, err := pageviewer.ReadJSONCookies(file)
+	require.NoError(t, err)
+	assert.Equal(t, fake.cookies, saved)
+}
+
+func TestBuildConfigKeepsDefaultPoolForJSONMultiMode(t *testing.T) {
 	opts := cliOptions{
 		url:        "https://example.com",
 		modes:      []string{"html", "links", "raw-text"},
@@ -139,8 +349,8 @@ func TestBuildConfigExpandsPoolForJSONMultiMode(t *testing.T) {
 	}
 
 	cfg, reqOpts := buildConfig(opts)
-	assert.Equal(t, 3, cfg.PoolSize)
-	assert.Equal(t, 3, cfg.Warmup)
+	assert.Equal(t, 1, cfg.PoolSize)
+	assert.Equal(t, 1, cfg.Warmup)
 	assert.Empty(t, reqOpts)
 }
 
@@ -240,6 +450,7 @@ func TestRunCLIJSONIncludesModesAndResultsForSingleMode(t *testing.T) {
 					StatusCode:  200,
 					FinalURL:    "https://example.com/final",
 					Header:      http.Header{"Content-Type": []string{"text/plain"}},
+					Charset:     "gbk",
 				}, nil
 			},
 		}, nil
@@ -262,6 +473,7 @@ func TestRunCLIJSONIncludesModesAndResultsForSingleMode(t *testing.T) {
 			StatusCode  int                 `json:"status_code"`
 			FinalURL    string              `json:"final_url"`
 			Header      map[string][]string `json:"header"`
+			Charset     string              `json:"charset"`
 		} `json:"results"`
 	}
 	require.NoError(t, json.Unmarshal(stdout.Bytes(), &got))
@@ -272,19 +484,23 @@ func TestRunCLIJSONIncludesModesAndResultsForSingleMode(t *testing.T) {
 	assert.Equal(t, 200, got.Results["raw-text"].StatusCode)
 	assert.Equal(t, "https://example.com/final", got.Results["raw-text"].FinalURL)
 	assert.Equal(t, []string{"text/plain"}, got.Results["raw-text"].Header["Content-Type"])
+	assert.Equal(t, "gbk", got.Results["raw-text"].Charset)
 }
 
 func TestRunCLIJSONSupportsMultipleModes(t *testing.T) {
+	var snapshotCalls int
 	original := startClient
 	startClient = func(ctx context.Context, cfg pageviewer.Config) (fetcher, error) {
 		return &fakeFetcher{
-			htmlFn: func(ctx context.Context, url string, opts ...pageviewer.RequestOption) (string, error) {
-				return "<html><body>html</body></html>", nil
-			},
-			articleFn: func(ctx context.Context, url string, opts ...pageviewer.RequestOption) (pageviewer.ReadabilityArticleWithMarkdown, error) {
-				return pageviewer.ReadabilityArticleWithMarkdown{
-					ReadbilityArticle: pageviewer.ReadbilityArticle{Title: "Example"},
-					Markdown:          "# Example",
+			snapshotFn: func(ctx context.Context, url string, so pageviewer.SnapshotOptions, opts ...pageviewer.RequestOption) (pageviewer.Snapshot, error) {
+				snapshotCalls++
+				assert.Equal(t, pageviewer.SnapshotOptions{HTML: true, Article: true}, so)
+				return pageviewer.Snapshot{
+					HTML: "<html><body>html</body></html>",
+					Article: pageviewer.ReadabilityArticleWithMarkdown{
+						ReadbilityArticle: pageviewer.ReadbilityArticle{Title: "Example"},
+						Markdown:          "# Example",
+					},
 				}, nil
 			},
 		}, nil
@@ -302,6 +518,7 @@ func TestRunCLIJSONSupportsMultipleModes(t *testing.T) {
 	}, &stdout, &stderr)
 	require.Equal(t, 0, code)
 	assert.Empty(t, stderr.String())
+	assert.Equal(t, 1, snapshotCalls)
 
 	var got struct {
 		Modes   []string `json:"modes"`
@@ -320,6 +537,358 @@ func TestRunCLIJSONSupportsMultipleModes(t *testing.T) {
 	assert.Equal(t, "# Example", got.Results["article"].Markdown)
 }
 
+func TestRunCLIJSONLinksIncludesStructuredLinks(t *testing.T) {
+	original := startClient
+	startClient = func(ctx context.Context, cfg pageviewer.Config) (fetcher, error) {
+		return &fakeFetcher{
+			snapshotFn: func(ctx context.Context, url string, so pageviewer.SnapshotOptions, opts ...pageviewer.RequestOption) (pageviewer.Snapshot, error) {
+				assert.Equal(t, pageviewer.SnapshotOptions{
+					Links:       true,
+					LinkOptions: pageviewer.LinkListOptions{Dedup: true, StripFragment: true},
+				}, so)
+				return pageviewer.Snapshot{
+					Links: `<a href="https://example.com/a">A</a>`,
+					LinkList: []pageviewer.Link{{
+						URL:      "https://example.com/a",
+						Text:     "A",
+						Rel:      "nofollow",
+						NoFollow: true,
+						Internal: true,
+						Source:   pageviewer.LinkSourceAnchor,
+					}},
+				}, nil
+			},
+		}, nil
+	}
+	t.Cleanup(func() { startClient = original })
+
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+
+	code := runCLI(context.Background(), []string{
+		"--url", "https://example.com",
+		"--json",
+		"--mode", "links",
+		"--dedup-links",
+		"--strip-fragments",
+	}, &stdout, &stderr)
+	require.Equal(t, 0, code)
+	assert.Empty(t, stderr.String())
+
+	var got struct {
+		Results struct {
+			Links struct {
+				Content string            `json:"content"`
+				Links   []pageviewer.Link `json:"links"`
+			} `json:"links"`
+		} `json:"results"`
+	}
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &got))
+	assert.Equal(t, `<a href="https://example.com/a">A</a>`, got.Results.Links.Content)
+	require.Len(t, got.Results.Links.Links, 1)
+	assert.True(t, got.Results.Links.Links[0].NoFollow)
+	assert.True(t, got.Results.Links.Links[0].Internal)
+	assert.Equal(t, pageviewer.LinkSourceAnchor, got.Results.Links.Links[0].Source)
+}
+
+func TestRunCLIMetadataPrintsJSONInTextMode(t *testing.T) {
+	original := startClient
+	startClient = func(ctx context.Context, cfg pageviewer.Config) (fetcher, error) {
+		return &fakeFetcher{
+			metadataFn: func(ctx context.Context, url string, opts ...pageviewer.RequestOption) (pageviewer.Metadata, error) {
+				return pageviewer.Metadata{
+					Title:     "Example",
+					OpenGraph: map[string][]string{"og:title": {"OG Example"}},
+				}, nil
+			},
+		}, nil
+	}
+	t.Cleanup(func() { startClient = original })
+
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+
+	code := runCLI(context.Background(), []string{"--url", "https://example.com", "--mode", "metadata"}, &stdout, &stderr)
+	require.Equal(t, 0, code)
+	assert.Empty(t, stderr.String())
+
+	var got pageviewer.Metadata
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &got))
+	assert.Equal(t, "Example", got.Title)
+	assert.Equal(t, []string{"OG Example"}, got.OpenGraph["og:title"])
+}
+
+func TestRunCLIJSONMultiModeIncludesMetadataInSnapshot(t *testing.T) {
+	original := startClient
+	startClient = func(ctx context.Context, cfg pageviewer.Config) (fetcher, error) {
+		return &fakeFetcher{
+			snapshotFn: func(ctx context.Context, url string, so pageviewer.SnapshotOptions, opts ...pageviewer.RequestOption) (pageviewer.Snapshot, error) {
+				assert.Equal(t, pageviewer.SnapshotOptions{HTML: true, Metadata: true}, so)
+				return pageviewer.Snapshot{
+					HTML:     "<html></html>",
+					Metadata: pageviewer.Metadata{Canonical: "https://example.com/canonical"},
+				}, nil
+			},
+		}, nil
+	}
+	t.Cleanup(func() { startClient = original })
+
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+
+	code := runCLI(context.Background(), []string{"--url", "https://example.com", "--json", "--mode", "html", "--mode", "metadata"}, &stdout, &stderr)
+	require.Equal(t, 0, code)
+	assert.Empty(t, stderr.String())
+
+	var got struct {
+		Results struct {
+			Metadata pageviewer.Metadata `json:"metadata"`
+		} `json:"results"`
+	}
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &got))
+	assert.Equal(t, "https://example.com/canonical", got.Results.Metadata.Canonical)
+}
+
+func TestRunCLIExtractPrintsJSON(t *testing.T) {
+	original := startClient
+	startClient = func(ctx context.Context, cfg pageviewer.Config) (fetcher, error) {
+		return &fakeFetcher{
+			extractFn: func(ctx context.Context, url string, schema pageviewer.Schema, opts ...pageviewer.RequestOption) (map[string]any, error) {
+				require.Len(t, schema.Fields, 1)
+				return map[string]any{"title": "Example <b>"}, nil
+			},
+		}, nil
+	}
+	t.Cleanup(func() { startClient = original })
+
+	path := filepath.Join(t.TempDir(), "schema.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"fields": [{"name": "title", "selector": "h1"}]}`), 0o644))
+
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+
+	code := runCLI(context.Background(), []string{"--url", "https://example.com", "--schema", path}, &stdout, &stderr)
+	require.Equal(t, 0, code)
+	assert.Empty(t, stderr.String())
+	assert.JSONEq(t, `{"title": "Example <b>"}`, stdout.String())
+}
+
+func TestRunCLIJSONMultiModeReportsSnapshotError(t *testing.T) {
+	original := startClient
+	startClient = func(ctx context.Context, cfg pageviewer.Config) (fetcher, error) {
+		return &fakeFetcher{
+			snapshotFn: func(ctx context.Context, url string, so pageviewer.SnapshotOptions, opts ...pageviewer.RequestOption) (pageviewer.Snapshot, error) {
+				return pageviewer.Snapshot{}, errors.New("snapshot failed")
+			},
+		}, nil
+	}
+	t.Cleanup(func() { startClient = original })
+
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+
+	code := runCLI(context.Background(), []string{
+		"--url", "https://example.com",
+		"--json",
+		"--mode", "links",
+		"--mode", "raw-text",
+	}, &stdout, &stderr)
+	require.Equal(t, 1, code)
+	assert.Empty(t, stdout.String())
+	assert.Contains(t, stderr.String(), "snapshot failed")
+}
+
+func TestRunCLIScreenshotWritesOutputFile(t *testing.T) {
+	original := startClient
+	startClient = func(ctx context.Context, cfg pageviewer.Config) (fetcher, error) {
+		return &fakeFetcher{
+			screenshotFn: func(ctx context.Context, url string, so pageviewer.ScreenshotOptions, opts ...pageviewer.RequestOption) ([]byte, error) {
+				assert.True(t, so.FullPage)
+				return []byte("png-bytes"), nil
+			},
+		}, nil
+	}
+	t.Cleanup(func() { startClient = original })
+
+	output := filepath.Join(t.TempDir(), "page.png")
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+
+	code := runCLI(context.Background(), []string{
+		"--url", "https://example.com",
+		"--mode", "screenshot",
+		"--full-page",
+		"--output", output,
+	}, &stdout, &stderr)
+	require.Equal(t, 0, code)
+	assert.Empty(t, stdout.String())
+	assert.Empty(t, stderr.String())
+
+	data, err := os.ReadFile(output)
+	require.NoError(t, err)
+	assert.Equal(t, "png-bytes", string(data))
+}
+
+func TestRunCLIScreenshotJSONUsesBase64(t *testing.T) {
+	original := startClient
+	startClient = func(ctx context.Context, cfg pageviewer.Config) (fetcher, error) {
+		return &fakeFetcher{
+			screenshotFn: func(ctx context.Context, url string, so pageviewer.ScreenshotOptions, opts ...pageviewer.RequestOption) ([]byte, error) {
+				return []byte("png-bytes"), nil
+			},
+		}, nil
+	}
+	t.Cleanup(func() { startClient = original })
+
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+
+	code := runCLI(context.Background(), []string{"--url", "https://example.com", "--mode", "screenshot", "--json"}, &stdout, &stderr)
+	require.Equal(t, 0, code)
+	assert.Empty(t, stderr.String())
+
+	var got struct {
+		Results map[string]struct {
+			Format string `json:"format"`
+			Data   string `json:"data"`
+		} `json:"results"`
+	}
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &got))
+	assert.Equal(t, "png", got.Results["screenshot"].Format)
+	assert.Equal(t, "cG5nLWJ5dGVz", got.Results["screenshot"].Data)
+}
+
+func TestRunCLIPDFWritesOutputFile(t *testing.T) {
+	original := startClient
+	startClient = func(ctx context.Context, cfg pageviewer.Config) (fetcher, error) {
+		return &fakeFetcher{
+			pdfFn: func(ctx context.Context, url string, po pageviewer.PDFOptions, opts ...pageviewer.RequestOption) ([]byte, error) {
+				assert.True(t, po.Landscape)
+				return []byte("%PDF-1.7"), nil
+			},
+		}, nil
+	}
+	t.Cleanup(func() { startClient = original })
+
+	output := filepath.Join(t.TempDir(), "page.pdf")
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+
+	code := runCLI(context.Background(), []string{
+		"--url", "https://example.com",
+		"--mode", "pdf",
+		"--landscape",
+		"--output", output,
+	}, &stdout, &stderr)
+	require.Equal(t, 0, code)
+	assert.Empty(t, stdout.String())
+	assert.Empty(t, stderr.String())
+
+	data, err := os.ReadFile(output)
+	require.NoError(t, err)
+	assert.Equal(t, "%PDF-1.7", string(data))
+}
+
+func TestRunCLIRawBytesWritesOutputFile(t *testing.T) {
+	original := startClient
+	startClient = func(ctx context.Context, cfg pageviewer.Config) (fetcher, error) {
+		return &fakeFetcher{
+			rawBytesFn: func(ctx context.Context, url string, opts ...pageviewer.RequestOption) (pageviewer.BytesResponse, error) {
+				ro := pageviewer.NewRequestOptions(opts...)
+				assert.Equal(t, int64(1024), ro.MaxBodySize)
+				assert.Equal(t, []string{"application/pdf", "image/*"}, ro.AllowedContentTypes)
+				return pageviewer.BytesResponse{Body: []byte{'%', 'P', 'D', 'F', 0x00, 0xff}, ContentType: "application/pdf"}, nil
+			},
+		}, nil
+	}
+	t.Cleanup(func() { startClient = original })
+
+	output := filepath.Join(t.TempDir(), "aaa.pdf")
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+
+	code := runCLI(context.Background(), []string{
+		"--url", "https://example.com/aaa.pdf",
+		"--mode", "raw-bytes",
+		"--max-body-size", "1024",
+		"--content-types", "application/pdf, image/*,",
+		"--output", output,
+	}, &stdout, &stderr)
+	require.Equal(t, 0, code)
+	assert.Empty(t, stdout.String())
+	assert.Empty(t, stderr.String())
+
+	data, err := os.ReadFile(output)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{'%', 'P', 'D', 'F', 0x00, 0xff}, data)
+
+	_, err = parseFlags([]string{"--url", "https://example.com", "--mode", "raw-bytes"})
+	assert.ErrorContains(t, err, "--output is required for --mode raw-bytes")
+	_, err = parseFlags([]string{"--url", "https://example.com", "--mode", "raw-bytes", "--json", "--max-body-size", "-1"})
+	assert.ErrorContains(t, err, "invalid --max-body-size: -1")
+}
+
+func TestRunCLIArchiveJSONReportsFormat(t *testing.T) {
+	original := startClient
+	startClient = func(ctx context.Context, cfg pageviewer.Config) (fetcher, error) {
+		return &fakeFetcher{
+			archiveFn: func(ctx context.Context, url string, ao pageviewer.ArchiveOptions, opts ...pageviewer.RequestOption) ([]byte, error) {
+				assert.Empty(t, ao.Format)
+				return []byte("MIME-Version: 1.0"), nil
+			},
+		}, nil
+	}
+	t.Cleanup(func() { startClient = original })
+
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+
+	code := runCLI(context.Background(), []string{"--url", "https://example.com", "--mode", "archive", "--json"}, &stdout, &stderr)
+	require.Equal(t, 0, code)
+	assert.Empty(t, stderr.String())
+
+	var got struct {
+		Results map[string]struct {
+			Format string `json:"format"`
+			Data   []byte `json:"data"`
+		} `json:"results"`
+	}
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &got))
+	assert.Equal(t, "mhtml", got.Results["archive"].Format)
+	assert.Equal(t, "MIME-Version: 1.0", string(got.Results["archive"].Data))
+}
+
+func TestRunCLIWARCWritesWarcinfoAndPassesWriter(t *testing.T) {
+	original := startClient
+	startClient = func(ctx context.Context, cfg pageviewer.Config) (fetcher, error) {
+		return &fakeFetcher{
+			htmlFn: func(ctx context.Context, url string, opts ...pageviewer.RequestOption) (string, error) {
+				assert.NotNil(t, pageviewer.NewRequestOptions(opts...).WARC)
+				return "<html></html>", nil
+			},
+		}, nil
+	}
+	t.Cleanup(func() { startClient = original })
+
+	output := filepath.Join(t.TempDir(), "capture.warc.gz")
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+
+	code := runCLI(context.Background(), []string{"--url", "https://example.com", "--warc", output}, &stdout, &stderr)
+	require.Equal(t, 0, code)
+	assert.Empty(t, stderr.String())
+
+	file, err := os.Open(output)
+	require.NoError(t, err)
+	defer file.Close()
+	reader, err := gzip.NewReader(file)
+	require.NoError(t, err)
+	data, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "WARC-Type: warcinfo")
+	assert.Contains(t, string(data), "filename: capture.warc.gz")
+}
+
 func TestRunCLIPrintsTraceIDOnFetchError(t *testing.T) {
 	original := startClient
 	startClient = func(ctx context.Context, cfg pageviewer.Config) (fetcher, error) {
@@ -429,11 +998,19 @@ func TestRunCLIHelpPrintsUsageAndReturnsZero(t *testing.T) {
 }
 
 type fakeFetcher struct {
-	closeFn   func() error
-	htmlFn    func(ctx context.Context, url string, opts ...pageviewer.RequestOption) (string, error)
-	linksFn   func(ctx context.Context, url string, opts ...pageviewer.RequestOption) (string, error)
-	articleFn func(ctx context.Context, url string, opts ...pageviewer.RequestOption) (pageviewer.ReadabilityArticleWithMarkdown, error)
-	rawTextFn func(ctx context.Context, url string, opts ...pageviewer.RequestOption) (pageviewer.TextResponse, error)
+	closeFn      func() error
+	htmlFn       func(ctx context.Context, url string, opts ...pageviewer.RequestOption) (string, error)
+	linksFn      func(ctx context.Context, url string, opts ...pageviewer.RequestOption) (string, error)
+	articleFn    func(ctx context.Context, url string, opts ...pageviewer.RequestOption) (pageviewer.ReadabilityArticleWithMarkdown, error)
+	rawTextFn    func(ctx context.Context, url string, opts ...pageviewer.RequestOption) (pageviewer.TextResponse, error)
+	snapshotFn   func(ctx context.Context, url string, so pageviewer.SnapshotOptions, opts ...pageviewer.RequestOption) (pageviewer.Snapshot, error)
+	screenshotFn func(ctx context.Context, url string, so pageviewer.ScreenshotOptions, opts ...pageviewer.RequestOption) ([]byte, error)
+	pdfFn        func(ctx context.Context, url string, po pageviewer.PDFOptions, opts ...pageviewer.RequestOption) ([]byte, error)
+	archiveFn    func(ctx context.Context, url string, ao pageviewer.ArchiveOptions, opts ...pageviewer.RequestOption) ([]byte, error)
+	metadataFn   func(ctx context.Context, url string, opts ...pageviewer.RequestOption) (pageviewer.Metadata, error)
+	extractFn    func(ctx context.Context, url string, schema pageviewer.Schema, opts ...pageviewer.RequestOption) (map[string]any, error)
+	rawBytesFn   func(ctx context.Context, url string, opts ...pageviewer.RequestOption) (pageviewer.BytesResponse, error)
+	cookies      []*http.Cookie
 }
 
 func (f *fakeFetcher) Close() error {
@@ -470,3 +1047,61 @@ func (f *fakeFetcher) RawText(ctx context.Context, url string, opts ...pageviewe
 	}
 	return pageviewer.TextResponse{}, errors.New("raw text not configured")
 }
+
+func (f *fakeFetcher) Snapshot(ctx context.Context, url string, so pageviewer.SnapshotOptions, opts ...pageviewer.RequestOption) (pageviewer.Snapshot, error) {
+	if f.snapshotFn != nil {
+		return f.snapshotFn(ctx, url, so, opts...)
+	}
+	return pageviewer.Snapshot{}, errors.New("snapshot not configured")
+}
+
+func (f *fakeFetcher) Screenshot(ctx context.Context, url string, so pageviewer.ScreenshotOptions, opts ...pageviewer.RequestOption) ([]byte, error) {
+	if f.screenshotFn != nil {
+		return f.screenshotFn(ctx, url, so, opts...)
+	}
+	return nil, errors.New("screenshot not configured")
+}
+
+func (f *fakeFetcher) PDF(ctx context.Context, url string, po pageviewer.PDFOptions, opts ...pageviewer.RequestOption) ([]byte, error) {
+	if f.pdfFn != nil {
+		return f.pdfFn(ctx, url, po, opts...)
+	}
+	return nil, errors.New("pdf not configured")
+}
+
+func (f *fakeFetcher) Archive(ctx context.Context, url string, ao pageviewer.ArchiveOptions, opts ...pageviewer.RequestOption) ([]byte, error) {
+	if f.archiveFn != nil {
+		return f.archiveFn(ctx, url, ao, opts...)
+	}
+	return nil, errors.New("archive not configured")
+}
+
+func (f *fakeFetcher) Metadata(ctx context.Context, url string, opts ...pageviewer.RequestOption) (pageviewer.Metadata, error) {
+	if f.metadataFn != nil {
+		return f.metadataFn(ctx, url, opts...)
+	}
+	return pageviewer.Metadata{}, errors.New("metadata not configured")
+}
+
+func (f *fakeFetcher) Extract(ctx context.Context, url string, schema pageviewer.Schema, opts ...pageviewer.RequestOption) (map[string]any, error) {
+	if f.extractFn != nil {
+		return f.extractFn(ctx, url, schema, opts...)
+	}
+	return nil, errors.New("extract not configured")
+}
+
+func (f *fakeFetcher) RawBytes(ctx context.Context, url string, opts ...pageviewer.RequestOption) (pageviewer.BytesResponse, error) {
+	if f.rawBytesFn != nil {
+		return f.rawBytesFn(ctx, url, opts...)
+	}
+	return pageviewer.BytesResponse{}, errors.New("raw bytes not configured")
+}
+
+func (f *fakeFetcher) Cookies(ctx context.Context, filter pageviewer.CookieFilter) ([]*http.Cookie, error) {
+	return f.cookies, nil
+}
+
+func (f *fakeFetcher) SetCookies(ctx context.Context, cookies []*http.Cookie) error {
+	f.cookies = append(f.cookies, cookies...)
+	return nil
+}
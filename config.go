@@ -7,18 +7,24 @@ import (
 )
 
 type Config struct {
-	PoolSize            int
-	AcquireTimeout      time.Duration
-	UserDataDir         string
-	Warmup              int
-	Debug               bool
-	NoHeadless          bool
-	DevTools            bool
-	Proxy               string
-	IgnoreCertErrors    bool
-	ChromePath          string
-	UserModeBrowser     bool
-	RemoteDebuggingPort int
+	PoolSize             int
+	AcquireTimeout       time.Duration
+	UserDataDir          string
+	Warmup               int
+	Debug                bool
+	NoHeadless           bool
+	DevTools             bool
+	Proxy                string
+	IgnoreCertErrors     bool
+	ChromePath           string
+	UserModeBrowser      bool
+	RemoteDebuggingPort  int
+	WaitStrategy         WaitStrategy       // 默认稳定等待策略，零值使用 WaitStrategyBalanced()，请求级可用 WithWaitStrategy 覆盖
+	DialogPolicy         DialogPolicy       // JS 对话框处理策略，零值全部接受，请求级可用 WithDialogPolicy 覆盖
+	WorkerReset          WorkerResetOptions // 归还 worker 前的状态重置，零值导航到 about:blank 并恢复默认的请求头和设备模拟
+	MaxRequestsPerWorker int                // 单个 worker 处理的请求数上限，达到后回收并补建，0 表示不限制
+	MaxWorkerAge         time.Duration      // 单个 worker 的存活时长上限，达到后在下一次归还时回收，0 表示不限制
+	MaxWorkerHeapSize    int64              // worker 页面已使用 JS 堆大小的上限，单位字节，超过后回收，0 表示不检查
 }
 
 func DefaultConfig() Config {
@@ -44,6 +50,9 @@ func (cfg Config) withDefaults() Config {
 	if cfg.Warmup > cfg.PoolSize {
 		cfg.Warmup = cfg.PoolSize
 	}
+	cfg.MaxRequestsPerWorker = max(cfg.MaxRequestsPerWorker, 0)
+	cfg.MaxWorkerAge = max(cfg.MaxWorkerAge, 0)
+	cfg.MaxWorkerHeapSize = max(cfg.MaxWorkerHeapSize, 0)
 
 	return cfg
 }
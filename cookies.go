@@ -0,0 +1,516 @@
+package pageviewer
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// 与浏览器交换的 cookie 使用 *http.Cookie 表示，Domain 沿用浏览器和 cookies.txt 的约定：
+// 以 . 开头表示同时发送给子域名，否则只发送给该主机；Expires 为零值表示会话 cookie
+
+// CookieFilter Cookies 的过滤条件，零值返回全部 cookie
+type CookieFilter struct {
+	Domain string // 只返回会发送给该主机的 cookie，例如 www.example.com 会匹配 .example.com
+	Name   string
+}
+
+func (f CookieFilter) match(cookie *http.Cookie) bool {
+	if f.Name != "" && cookie.Name != f.Name {
+		return false
+	}
+	if f.Domain == "" {
+		return true
+	}
+	host := strings.ToLower(strings.TrimPrefix(f.Domain, "."))
+	domain := strings.ToLower(cookie.Domain)
+	if strings.HasPrefix(domain, ".") {
+		domain = domain[1:]
+		return host == domain || strings.HasSuffix(host, "."+domain)
+	}
+	return host == domain
+}
+
+// Cookies 返回共享 Browser 中匹配 filter 的 cookie
+func (c *Client) Cookies(ctx context.Context, filter CookieFilter) ([]*http.Cookie, error) {
+	if err := c.beginTrackedOperation(); err != nil {
+		return nil, err
+	}
+	defer c.inflight.Done()
+	if c.browser == nil || c.browser.Browser == nil {
+		return nil, ErrBrowserUnavailable
+	}
+
+	cookies, err := c.browser.Browser.Context(ctx).GetCookies()
+	if err != nil {
+		return nil, err
+	}
+
+	var result []*http.Cookie
+	for _, cookie := range cookies {
+		if hc := httpCookie(cookie); filter.match(hc) {
+			result = append(result, hc)
+		}
+	}
+	return result, nil
+}
+
+// SetCookies 把 cookie 写入共享 Browser，所有 worker 的后续请求都会携带；同名、同域、同路径的 cookie 会被覆盖
+func (c *Client) SetCookies(ctx context.Context, cookies []*http.Cookie) error {
+	params, err := cookieParams(cookies)
+	if err != nil {
+		return err
+	}
+	if err := c.beginTrackedOperation(); err != nil {
+		return err
+	}
+	defer c.inflight.Done()
+	if c.browser == nil || c.browser.Browser == nil {
+		return ErrBrowserUnavailable
+	}
+	if len(params) == 0 {
+		return nil
+	}
+	return c.browser.Browser.Context(ctx).SetCookies(params)
+}
+
+// ClearCookies 清空共享 Browser 中的全部 cookie
+func (c *Client) ClearCookies(ctx context.Context) error {
+	if err := c.beginTrackedOperation(); err != nil {
+		return err
+	}
+	defer c.inflight.Done()
+	if c.browser == nil || c.browser.Browser == nil {
+		return ErrBrowserUnavailable
+	}
+	return c.browser.Browser.Context(ctx).SetCookies(nil)
+}
+
+// cookieOverrideKey 浏览器上下文中按名称、域名和路径区分的一个 cookie
+type cookieOverrideKey struct {
+	name   string
+	domain string
+	path   string
+}
+
+// cookieOverride 被请求级 cookie 覆盖的 cookie，refs 为仍在覆盖它的请求数
+type cookieOverride struct {
+	refs     int
+	original *proto.NetworkCookieParam // 第一个请求写入前的 cookie，nil 表示原本不存在
+	value    string                    // 最近一次写入的值
+}
+
+// cookieContextKey 一个浏览器中的一个浏览器上下文
+type cookieContextKey struct {
+	browser   *rod.Browser
+	contextID proto.BrowserBrowserContextID
+}
+
+// cookieContext 一个浏览器上下文中正在被请求级 cookie 覆盖的 cookie；
+// mu 串行化同一上下文里的读取、写入和恢复，不同上下文的请求互不等待
+type cookieContext struct {
+	mu        sync.Mutex
+	users     int // 已经取得或正在等待 mu 的调用数，只在 cookieContexts 的锁内修改
+	overrides map[cookieOverrideKey]*cookieOverride
+}
+
+// cookieContexts 按浏览器上下文保存覆盖记录，并发请求覆盖同一个 cookie 时只在最后一个请求结束时恢复；
+// 表本身的锁只在查找和移除记录时持有，不跨越 CDP 调用
+var cookieContexts = struct {
+	sync.Mutex
+	m map[cookieContextKey]*cookieContext
+}{m: make(map[cookieContextKey]*cookieContext)}
+
+// lockCookieContext 取得浏览器上下文的覆盖记录并加锁，返回的 unlock 在没有其他使用者且没有覆盖记录时把它从表中移除
+func lockCookieContext(key cookieContextKey) (*cookieContext, func()) {
+	cookieContexts.Lock()
+	cc := cookieContexts.m[key]
+	if cc == nil {
+		cc = &cookieContext{overrides: make(map[cookieOverrideKey]*cookieOverride)}
+		cookieContexts.m[key] = cc
+	}
+	cc.users++
+	cookieContexts.Unlock()
+
+	cc.mu.Lock()
+	return cc, func() {
+		cc.mu.Unlock()
+
+		cookieContexts.Lock()
+		defer cookieContexts.Unlock()
+		// users 归零时没有其他调用持有或等待 mu，可以直接读取 overrides
+		if cc.users--; cc.users == 0 && len(cc.overrides) == 0 {
+			delete(cookieContexts.m, key)
+		}
+	}
+}
+
+// applyCookies 在 worker 页面所在的浏览器上下文中写入请求级 cookie，返回恢复函数。
+// cookie 存放在整个浏览器上下文中，请求期间同一上下文里其他 worker 的请求也会带上它；
+// 最后一个覆盖该 cookie 的请求结束时，如果 cookie 仍是写入的值就删除并还原被覆盖的同名、同域、同路径的 cookie，
+// 期间被网站改写或删除的 cookie 保持网站设置的结果
+func applyCookies(page *rod.Page, params []*proto.NetworkCookieParam) (func() error, error) {
+	info, err := page.Info()
+	if err != nil {
+		return nil, err
+	}
+	browser := page.Browser()
+	getCookies := func() ([]*proto.NetworkCookie, error) {
+		res, err := proto.StorageGetCookies{BrowserContextID: info.BrowserContextID}.Call(browser)
+		if err != nil {
+			return nil, err
+		}
+		return res.Cookies, nil
+	}
+	keys := make([]cookieOverrideKey, 0, len(params))
+	for _, param := range params {
+		keys = append(keys, cookieOverrideKey{
+			name:   param.Name,
+			domain: cookieParamDomain(param),
+			path:   param.Path,
+		})
+	}
+	contextKey := cookieContextKey{browser: browser, contextID: info.BrowserContextID}
+
+	cc, unlock := lockCookieContext(contextKey)
+	defer unlock()
+
+	existing, err := getCookies()
+	if err != nil {
+		return nil, err
+	}
+	var acquired []cookieOverrideKey
+	reset := func() error {
+		cc, unlock := lockCookieContext(contextKey)
+		defer unlock()
+		return cc.release(page, acquired, getCookies)
+	}
+	for i, key := range keys {
+		o := cc.overrides[key]
+		if o == nil {
+			o = &cookieOverride{}
+			if cookie := findCookie(existing, key); cookie != nil {
+				original, err := cookieParams([]*http.Cookie{httpCookie(cookie)})
+				if err != nil {
+					return nil, errors.Join(err, cc.release(page, acquired, getCookies))
+				}
+				o.original = original[0]
+			}
+			cc.overrides[key] = o
+		}
+		o.refs++
+		o.value = params[i].Value
+		acquired = append(acquired, key)
+	}
+
+	if err := (proto.NetworkSetCookies{Cookies: params}).Call(page); err != nil {
+		return nil, errors.Join(err, cc.release(page, acquired, getCookies))
+	}
+	return reset, nil
+}
+
+// release 释放 keys 对应的覆盖记录，调用方需要持有 cc.mu
+func (cc *cookieContext) release(page *rod.Page, keys []cookieOverrideKey, getCookies func() ([]*proto.NetworkCookie, error)) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	current, err := getCookies()
+	if err != nil {
+		// 读取失败时仍然释放引用计数，避免记录一直留在表中
+		for _, key := range keys {
+			if o := cc.overrides[key]; o != nil {
+				if o.refs--; o.refs <= 0 {
+					delete(cc.overrides, key)
+				}
+			}
+		}
+		return err
+	}
+
+	var errs []error
+	for _, key := range keys {
+		o := cc.overrides[key]
+		if o == nil {
+			continue
+		}
+		if o.refs--; o.refs > 0 {
+			continue
+		}
+		delete(cc.overrides, key)
+
+		cookie := findCookie(current, key)
+		if cookie == nil || cookie.Value != o.value {
+			continue
+		}
+		errs = append(errs, proto.NetworkDeleteCookies{Name: key.name, Domain: key.domain, Path: key.path}.Call(page))
+		if o.original != nil {
+			errs = append(errs, proto.NetworkSetCookies{Cookies: []*proto.NetworkCookieParam{o.original}}.Call(page))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func findCookie(cookies []*proto.NetworkCookie, key cookieOverrideKey) *proto.NetworkCookie {
+	for _, cookie := range cookies {
+		if cookie.Name == key.name && cookie.Domain == key.domain && cookie.Path == key.path {
+			return cookie
+		}
+	}
+	return nil
+}
+
+// cookieParams 把 http.Cookie 转成 CDP 参数，Domain 不以 . 开头时按 URL 写入，得到只发送给该主机的 cookie
+func cookieParams(cookies []*http.Cookie) ([]*proto.NetworkCookieParam, error) {
+	params := make([]*proto.NetworkCookieParam, 0, len(cookies))
+	for _, cookie := range cookies {
+		if cookie == nil {
+			continue
+		}
+		domain := strings.ToLower(strings.TrimSpace(cookie.Domain))
+		if cookie.Name == "" || domain == "" || domain == "." {
+			return nil, fmt.Errorf("pageviewer: cookie %q requires name and domain", cookie.Name)
+		}
+
+		param := &proto.NetworkCookieParam{
+			Name:     cookie.Name,
+			Value:    cookie.Value,
+			Path:     cookie.Path,
+			Secure:   cookie.Secure,
+			HTTPOnly: cookie.HttpOnly,
+		}
+		if param.Path == "" {
+			param.Path = "/"
+		}
+		if strings.HasPrefix(domain, ".") {
+			param.Domain = domain
+		} else {
+			scheme := "http"
+			if cookie.Secure {
+				scheme = "https"
+			}
+			param.URL = scheme + "://" + domain + param.Path
+		}
+
+		switch cookie.SameSite {
+		case http.SameSiteLaxMode:
+			param.SameSite = proto.NetworkCookieSameSiteLax
+		case http.SameSiteStrictMode:
+			param.SameSite = proto.NetworkCookieSameSiteStrict
+		case http.SameSiteNoneMode:
+			param.SameSite = proto.NetworkCookieSameSiteNone
+		}
+
+		switch {
+		case cookie.MaxAge > 0:
+			param.Expires = proto.TimeSinceEpoch(time.Now().Add(time.Duration(cookie.MaxAge) * time.Second).Unix())
+		case !cookie.Expires.IsZero():
+			param.Expires = proto.TimeSinceEpoch(cookie.Expires.Unix())
+		}
+		params = append(params, param)
+	}
+	return params, nil
+}
+
+// cookieParamDomain 返回 cookie 写入后在浏览器中的域名
+func cookieParamDomain(param *proto.NetworkCookieParam) string {
+	if param.Domain != "" {
+		return param.Domain
+	}
+	domain := param.URL[strings.Index(param.URL, "://")+3:]
+	return strings.TrimSuffix(domain, param.Path)
+}
+
+func httpCookie(cookie *proto.NetworkCookie) *http.Cookie {
+	hc := &http.Cookie{
+		Name:     cookie.Name,
+		Value:    cookie.Value,
+		Domain:   cookie.Domain,
+		Path:     cookie.Path,
+		Secure:   cookie.Secure,
+		HttpOnly: cookie.HTTPOnly,
+	}
+	if !cookie.Session && cookie.Expires > 0 {
+		hc.Expires = time.Unix(int64(cookie.Expires), 0)
+	}
+	switch cookie.SameSite {
+	case proto.NetworkCookieSameSiteLax:
+		hc.SameSite = http.SameSiteLaxMode
+	case proto.NetworkCookieSameSiteStrict:
+		hc.SameSite = http.SameSiteStrictMode
+	case proto.NetworkCookieSameSiteNone:
+		hc.SameSite = http.SameSiteNoneMode
+	}
+	return hc
+}
+
+const netscapeHTTPOnlyPrefix = "#HttpOnly_"
+
+// ReadNetscapeCookies 读取 curl / wget 使用的 Netscape cookies.txt 格式，支持 #HttpOnly_ 前缀
+func ReadNetscapeCookies(r io.Reader) ([]*http.Cookie, error) {
+	var cookies []*http.Cookie
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimRight(scanner.Text(), "\r")
+		httpOnly := strings.HasPrefix(text, netscapeHTTPOnlyPrefix)
+		if httpOnly {
+			text = strings.TrimPrefix(text, netscapeHTTPOnlyPrefix)
+		} else if strings.TrimSpace(text) == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fields := strings.Split(text, "\t")
+		if len(fields) != 7 {
+			return nil, fmt.Errorf("pageviewer: invalid netscape cookie at line %d", line)
+		}
+		expires, err := strconv.ParseInt(fields[4], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("pageviewer: invalid netscape cookie expiry at line %d: %w", line, err)
+		}
+
+		domain := strings.TrimPrefix(fields[0], ".")
+		if strings.EqualFold(fields[1], "TRUE") {
+			domain = "." + domain
+		}
+		cookie := &http.Cookie{
+			Name:     fields[5],
+			Value:    fields[6],
+			Domain:   domain,
+			Path:     fields[2],
+			Secure:   strings.EqualFold(fields[3], "TRUE"),
+			HttpOnly: httpOnly,
+		}
+		if expires > 0 {
+			cookie.Expires = time.Unix(expires, 0)
+		}
+		cookies = append(cookies, cookie)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return cookies, nil
+}
+
+// WriteNetscapeCookies 以 Netscape cookies.txt 格式写出，会话 cookie 的过期时间写为 0
+func WriteNetscapeCookies(w io.Writer, cookies []*http.Cookie) error {
+	bw := bufio.NewWriter(w)
+	_, _ = bw.WriteString("# Netscape HTTP Cookie File\n")
+	for _, cookie := range cookies {
+		if cookie == nil {
+			continue
+		}
+		prefix := ""
+		if cookie.HttpOnly {
+			prefix = netscapeHTTPOnlyPrefix
+		}
+		var expires int64
+		if !cookie.Expires.IsZero() {
+			expires = cookie.Expires.Unix()
+		}
+		path := cookie.Path
+		if path == "" {
+			path = "/"
+		}
+		_, _ = fmt.Fprintf(bw, "%s%s\t%s\t%s\t%s\t%d\t%s\t%s\n",
+			prefix, cookie.Domain, netscapeBool(strings.HasPrefix(cookie.Domain, ".")), path,
+			netscapeBool(cookie.Secure), expires, cookie.Name, cookie.Value)
+	}
+	return bw.Flush()
+}
+
+func netscapeBool(v bool) string {
+	if v {
+		return "TRUE"
+	}
+	return "FALSE"
+}
+
+// jsonCookie JSON 格式的 cookie，字段与 Playwright storageState 中的 cookies 一致，expires 为 Unix 秒，-1 表示会话 cookie
+type jsonCookie struct {
+	Name     string  `json:"name"`
+	Value    string  `json:"value"`
+	Domain   string  `json:"domain"`
+	Path     string  `json:"path"`
+	Expires  float64 `json:"expires"`
+	HTTPOnly bool    `json:"httpOnly"`
+	Secure   bool    `json:"secure"`
+	SameSite string  `json:"sameSite,omitempty"`
+}
+
+// ReadJSONCookies 读取 JSON 数组格式的 cookie
+func ReadJSONCookies(r io.Reader) ([]*http.Cookie, error) {
+	var items []jsonCookie
+	if err := json.NewDecoder(r).Decode(&items); err != nil {
+		return nil, fmt.Errorf("pageviewer: invalid json cookies: %w", err)
+	}
+
+	cookies := make([]*http.Cookie, 0, len(items))
+	for _, item := range items {
+		cookie := &http.Cookie{
+			Name:     item.Name,
+			Value:    item.Value,
+			Domain:   item.Domain,
+			Path:     item.Path,
+			Secure:   item.Secure,
+			HttpOnly: item.HTTPOnly,
+		}
+		if item.Expires > 0 {
+			cookie.Expires = time.Unix(int64(item.Expires), 0)
+		}
+		switch strings.ToLower(item.SameSite) {
+		case "lax":
+			cookie.SameSite = http.SameSiteLaxMode
+		case "strict":
+			cookie.SameSite = http.SameSiteStrictMode
+		case "none":
+			cookie.SameSite = http.SameSiteNoneMode
+		}
+		cookies = append(cookies, cookie)
+	}
+	return cookies, nil
+}
+
+// WriteJSONCookies 以带缩进的 JSON 数组格式写出
+func WriteJSONCookies(w io.Writer, cookies []*http.Cookie) error {
+	items := make([]jsonCookie, 0, len(cookies))
+	for _, cookie := range cookies {
+		if cookie == nil {
+			continue
+		}
+		item := jsonCookie{
+			Name:     cookie.Name,
+			Value:    cookie.Value,
+			Domain:   cookie.Domain,
+			Path:     cookie.Path,
+			Expires:  -1,
+			HTTPOnly: cookie.HttpOnly,
+			Secure:   cookie.Secure,
+		}
+		if !cookie.Expires.IsZero() {
+			item.Expires = float64(cookie.Expires.Unix())
+		}
+		switch cookie.SameSite {
+		case http.SameSiteLaxMode:
+			item.SameSite = "Lax"
+		case http.SameSiteStrictMode:
+			item.SameSite = "Strict"
+		case http.SameSiteNoneMode:
+			item.SameSite = "None"
+		}
+		items = append(items, item)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(items)
+}
@@ -0,0 +1,227 @@
+package pageviewer
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNetscapeCookiesRoundTrip(t *testing.T) {
+	input := strings.Join([]string{
+		"# Netscape HTTP Cookie File",
+		"",
+		".example.com\tTRUE\t/\tTRUE\t1893456000\tsid\tabc",
+		"#HttpOnly_www.example.com\tFALSE\t/app\tFALSE\t0\ttoken\t",
+	}, "\n")
+
+	cookies, err := ReadNetscapeCookies(strings.NewReader(input))
+	require.NoError(t, err)
+	require.Len(t, cookies, 2)
+	assert.Equal(t, &http.Cookie{Name: "sid", Value: "abc", Domain: ".example.com", Path: "/", Secure: true, Expires: time.Unix(1893456000, 0)}, cookies[0])
+	assert.Equal(t, &http.Cookie{Name: "token", Domain: "www.example.com", Path: "/app", HttpOnly: true}, cookies[1])
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteNetscapeCookies(&buf, cookies))
+	again, err := ReadNetscapeCookies(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, cookies, again)
+
+	_, err = ReadNetscapeCookies(strings.NewReader("example.com\tFALSE\t/\n"))
+	assert.ErrorContains(t, err, "line 1")
+}
+
+func TestJSONCookiesRoundTrip(t *testing.T) {
+	cookies := []*http.Cookie{
+		{Name: "sid", Value: "abc", Domain: ".example.com", Path: "/", Secure: true, HttpOnly: true, SameSite: http.SameSiteLaxMode, Expires: time.Unix(1893456000, 0)},
+		{Name: "session", Value: "1", Domain: "www.example.com", Path: "/"},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteJSONCookies(&buf, cookies))
+	assert.Contains(t, buf.String(), `"expires": -1`)
+	assert.Contains(t, buf.String(), `"sameSite": "Lax"`)
+
+	again, err := ReadJSONCookies(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, cookies, again)
+}
+
+func TestCookieParams(t *testing.T) {
+	params, err := cookieParams([]*http.Cookie{
+		{Name: "a", Value: "1", Domain: ".Example.com"},
+		{Name: "b", Value: "2", Domain: "www.example.com", Path: "/app", Secure: true, SameSite: http.SameSiteStrictMode},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &proto.NetworkCookieParam{Name: "a", Value: "1", Domain: ".example.com", Path: "/"}, params[0])
+	assert.Equal(t, &proto.NetworkCookieParam{Name: "b", Value: "2", URL: "https://www.example.com/app", Path: "/app", Secure: true, SameSite: proto.NetworkCookieSameSiteStrict}, params[1])
+	assert.Equal(t, "www.example.com", cookieParamDomain(params[1]))
+
+	_, err = cookieParams([]*http.Cookie{{Name: "a"}})
+	assert.Error(t, err)
+}
+
+func TestCookieFilterMatch(t *testing.T) {
+	domainCookie := &http.Cookie{Name: "sid", Domain: ".example.com"}
+	hostCookie := &http.Cookie{Name: "sid", Domain: "example.com"}
+
+	assert.True(t, CookieFilter{}.match(domainCookie))
+	assert.True(t, CookieFilter{Domain: "www.example.com"}.match(domainCookie))
+	assert.False(t, CookieFilter{Domain: "www.example.com"}.match(hostCookie))
+	assert.True(t, CookieFilter{Domain: "example.com", Name: "sid"}.match(hostCookie))
+	assert.False(t, CookieFilter{Name: "other"}.match(domainCookie))
+	assert.False(t, CookieFilter{Domain: "badexample.com"}.match(domainCookie))
+}
+
+func TestClientCookies(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		var names []string
+		for _, cookie := range r.Cookies() {
+			names = append(names, cookie.Name+"="+cookie.Value)
+		}
+		_, _ = w.Write([]byte(`<html><body><p id="cookies">` + strings.Join(names, ";") + `</p></body></html>`))
+	}))
+	defer s.Close()
+
+	client := newTestClient(t, Config{PoolSize: 1, Warmup: 1})
+	ctx := context.Background()
+	require.NoError(t, client.ClearCookies(ctx))
+
+	require.NoError(t, client.SetCookies(ctx, []*http.Cookie{{Name: "shared", Value: "1", Domain: "127.0.0.1"}}))
+	cookies, err := client.Cookies(ctx, CookieFilter{Domain: "127.0.0.1"})
+	require.NoError(t, err)
+	require.Len(t, cookies, 1)
+	assert.Equal(t, "shared", cookies[0].Name)
+
+	html, err := client.HTML(ctx, s.URL, WithCookies(&http.Cookie{Name: "shared", Value: "2", Domain: "127.0.0.1"}, &http.Cookie{Name: "once", Value: "x", Domain: "127.0.0.1"}))
+	require.NoError(t, err)
+	assert.Contains(t, html, "shared=2")
+	assert.Contains(t, html, "once=x")
+
+	// 请求级 cookie 在归还 worker 前删除，被覆盖的共享 cookie 恢复原值
+	html, err = client.HTML(ctx, s.URL)
+	require.NoError(t, err)
+	assert.Contains(t, html, "shared=1")
+	assert.NotContains(t, html, "once=x")
+
+	require.NoError(t, client.ClearCookies(ctx))
+	cookies, err = client.Cookies(ctx, CookieFilter{})
+	require.NoError(t, err)
+	assert.Empty(t, cookies)
+}
+
+func TestClientRequestCookiesWithConcurrentRequests(t *testing.T) {
+	entered := make(chan struct{}, 2)
+	gates := map[string]chan struct{}{"1": make(chan struct{}), "2": make(chan struct{})}
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		switch r.URL.Path {
+		case "/slow":
+			entered <- struct{}{}
+			<-gates[r.URL.Query().Get("gate")]
+		case "/set":
+			http.SetCookie(w, &http.Cookie{Name: "sid", Value: "site", Path: "/"})
+		}
+		var names []string
+		for _, cookie := range r.Cookies() {
+			names = append(names, cookie.Name+"="+cookie.Value)
+		}
+		_, _ = w.Write([]byte(`<html><body><p id="cookies">` + strings.Join(names, ";") + `</p></body></html>`))
+	}))
+	defer s.Close()
+
+	client := newTestClient(t, Config{PoolSize: 3, Warmup: 3})
+	ctx := context.Background()
+	require.NoError(t, client.ClearCookies(ctx))
+	defer func() {
+		_ = client.ClearCookies(ctx)
+	}()
+
+	visit := func(path string, cookies ...*http.Cookie) <-chan error {
+		done := make(chan error, 1)
+		go func() {
+			_, err := client.HTML(ctx, s.URL+path, WithWaitTimeout(5*time.Second), WithCookies(cookies...))
+			done <- err
+		}()
+		return done
+	}
+	sidCookies := func() []*http.Cookie {
+		cookies, err := client.Cookies(ctx, CookieFilter{Name: "sid"})
+		require.NoError(t, err)
+		return cookies
+	}
+
+	// 请求级 cookie 写入整个浏览器上下文，请求期间其他 worker 的请求也会带上它
+	slow := visit("/slow?gate=1", &http.Cookie{Name: "sid", Value: "req", Domain: "127.0.0.1"})
+	<-entered
+	html, err := client.HTML(ctx, s.URL+"/echo")
+	require.NoError(t, err)
+	assert.Contains(t, html, "sid=req")
+
+	// 请求期间网站改写的 cookie 在恢复时保留
+	_, err = client.HTML(ctx, s.URL+"/set")
+	require.NoError(t, err)
+	close(gates["1"])
+	require.NoError(t, <-slow)
+	cookies := sidCookies()
+	require.Len(t, cookies, 1)
+	assert.Equal(t, "site", cookies[0].Value)
+
+	// 并发请求覆盖同一个 cookie 时，最后一个请求结束后才恢复到覆盖前的值
+	first := visit("/slow?gate=2", &http.Cookie{Name: "sid", Value: "a", Domain: "127.0.0.1"})
+	second := visit("/slow?gate=2", &http.Cookie{Name: "sid", Value: "b", Domain: "127.0.0.1"})
+	<-entered
+	<-entered
+	close(gates["2"])
+	require.NoError(t, <-first)
+	require.NoError(t, <-second)
+	cookies = sidCookies()
+	require.Len(t, cookies, 1)
+	assert.Equal(t, "site", cookies[0].Value)
+	cookieContexts.Lock()
+	assert.Empty(t, cookieContexts.m)
+	cookieContexts.Unlock()
+}
+
+func TestLockCookieContextScopesLockPerContext(t *testing.T) {
+	first := cookieContextKey{contextID: "first"}
+	second := cookieContextKey{contextID: "second"}
+
+	cc, unlock := lockCookieContext(first)
+	cc.overrides[cookieOverrideKey{name: "sid", domain: "example.com", path: "/"}] = &cookieOverride{refs: 1}
+
+	// 另一个上下文不需要等待 first 释放
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, unlockSecond := lockCookieContext(second)
+		unlockSecond()
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("lock on another browser context blocked")
+	}
+	unlock()
+
+	cookieContexts.Lock()
+	assert.Contains(t, cookieContexts.m, first)
+	assert.NotContains(t, cookieContexts.m, second)
+	cookieContexts.Unlock()
+
+	cc, unlock = lockCookieContext(first)
+	clear(cc.overrides)
+	unlock()
+
+	cookieContexts.Lock()
+	assert.NotContains(t, cookieContexts.m, first)
+	cookieContexts.Unlock()
+}
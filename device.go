@@ -0,0 +1,179 @@
+package pageviewer
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/devices"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// DeviceProfile 设备、视口和媒体特性模拟配置，零值字段表示不覆盖
+type DeviceProfile struct {
+	Name              string
+	Width             int
+	Height            int
+	DeviceScaleFactor float64 // 默认 1
+	Mobile            bool    // 移动端视口，影响 meta viewport 和滚动条
+	Touch             bool    // 启用触摸事件，navigator.maxTouchPoints 为 5
+	UserAgent         string  // 为空时沿用浏览器默认值，WithUserAgent 优先
+	Media             string  // screen 或 print
+	ColorScheme       string  // prefers-color-scheme：light 或 dark
+	ReducedMotion     string  // prefers-reduced-motion：reduce 或 no-preference
+}
+
+const (
+	userAgentDesktopChrome = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36"
+	userAgentMacChrome     = "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36"
+	userAgentIPhone        = "Mozilla/5.0 (iPhone; CPU iPhone OS 17_4 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4 Mobile/15E148 Safari/604.1"
+	userAgentIPad          = "Mozilla/5.0 (iPad; CPU OS 17_4 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4 Mobile/15E148 Safari/604.1"
+	userAgentAndroid       = "Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Mobile Safari/537.36"
+	userAgentGalaxy        = "Mozilla/5.0 (Linux; Android 14; SM-S921B) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Mobile Safari/537.36"
+)
+
+// 内置设备目录
+var (
+	DeviceDesktop1080p = DeviceProfile{Name: "desktop-1080p", Width: 1920, Height: 1080, DeviceScaleFactor: 1, UserAgent: userAgentDesktopChrome}
+	DeviceDesktop720p  = DeviceProfile{Name: "desktop-720p", Width: 1280, Height: 720, DeviceScaleFactor: 1, UserAgent: userAgentDesktopChrome}
+	DeviceMacBookPro   = DeviceProfile{Name: "macbook-pro", Width: 1512, Height: 982, DeviceScaleFactor: 2, UserAgent: userAgentMacChrome}
+	DeviceIPhone15     = DeviceProfile{Name: "iphone-15", Width: 393, Height: 852, DeviceScaleFactor: 3, Mobile: true, Touch: true, UserAgent: userAgentIPhone}
+	DeviceIPhoneSE     = DeviceProfile{Name: "iphone-se", Width: 375, Height: 667, DeviceScaleFactor: 2, Mobile: true, Touch: true, UserAgent: userAgentIPhone}
+	DeviceIPadAir      = DeviceProfile{Name: "ipad-air", Width: 820, Height: 1180, DeviceScaleFactor: 2, Mobile: true, Touch: true, UserAgent: userAgentIPad}
+	DevicePixel8       = DeviceProfile{Name: "pixel-8", Width: 412, Height: 915, DeviceScaleFactor: 2.625, Mobile: true, Touch: true, UserAgent: userAgentAndroid}
+	DeviceGalaxyS24    = DeviceProfile{Name: "galaxy-s24", Width: 360, Height: 780, DeviceScaleFactor: 3, Mobile: true, Touch: true, UserAgent: userAgentGalaxy}
+)
+
+// DeviceProfiles 返回内置设备目录
+func DeviceProfiles() []DeviceProfile {
+	return []DeviceProfile{
+		DeviceDesktop1080p,
+		DeviceDesktop720p,
+		DeviceMacBookPro,
+		DeviceIPhone15,
+		DeviceIPhoneSE,
+		DeviceIPadAir,
+		DevicePixel8,
+		DeviceGalaxyS24,
+	}
+}
+
+// DeviceByName 按名称返回内置设备，名称不区分大小写
+func DeviceByName(name string) (DeviceProfile, error) {
+	for _, device := range DeviceProfiles() {
+		if strings.EqualFold(device.Name, strings.TrimSpace(name)) {
+			return device, nil
+		}
+	}
+	return DeviceProfile{}, fmt.Errorf("pageviewer: unknown device %q", name)
+}
+
+// Landscape 返回宽高互换的横屏副本
+func (d DeviceProfile) Landscape() DeviceProfile {
+	d.Width, d.Height = d.Height, d.Width
+	if d.Name != "" {
+		d.Name += "-landscape"
+	}
+	return d
+}
+
+func (d DeviceProfile) validate() error {
+	if d.Width < 0 || d.Height < 0 || (d.Width == 0) != (d.Height == 0) {
+		return fmt.Errorf("pageviewer: invalid device viewport %dx%d", d.Width, d.Height)
+	}
+	if d.DeviceScaleFactor < 0 {
+		return fmt.Errorf("pageviewer: invalid device scale factor %v", d.DeviceScaleFactor)
+	}
+	switch d.Media {
+	case "", "screen", "print":
+	default:
+		return fmt.Errorf("pageviewer: invalid device media %q", d.Media)
+	}
+	switch d.ColorScheme {
+	case "", "light", "dark":
+	default:
+		return fmt.Errorf("pageviewer: invalid device color scheme %q", d.ColorScheme)
+	}
+	switch d.ReducedMotion {
+	case "", "reduce", "no-preference":
+	default:
+		return fmt.Errorf("pageviewer: invalid device reduced motion %q", d.ReducedMotion)
+	}
+	return nil
+}
+
+func (d DeviceProfile) metrics() *proto.EmulationSetDeviceMetricsOverride {
+	scale := d.DeviceScaleFactor
+	if scale == 0 {
+		scale = 1
+	}
+	return &proto.EmulationSetDeviceMetricsOverride{
+		Width:             d.Width,
+		Height:            d.Height,
+		DeviceScaleFactor: scale,
+		Mobile:            d.Mobile,
+		ScreenWidth:       &d.Width,
+		ScreenHeight:      &d.Height,
+	}
+}
+
+func (d DeviceProfile) emulatedMedia() proto.EmulationSetEmulatedMedia {
+	media := proto.EmulationSetEmulatedMedia{Media: d.Media}
+	if d.ColorScheme != "" {
+		media.Features = append(media.Features, &proto.EmulationMediaFeature{Name: "prefers-color-scheme", Value: d.ColorScheme})
+	}
+	if d.ReducedMotion != "" {
+		media.Features = append(media.Features, &proto.EmulationMediaFeature{Name: "prefers-reduced-motion", Value: d.ReducedMotion})
+	}
+	return media
+}
+
+// applyDevice 应用视口、触摸和媒体特性模拟，返回恢复到 baseline 的函数；User-Agent 由 applyOverrides 统一处理
+func applyDevice(page *rod.Page, baseline devices.Device, d DeviceProfile) (func() error, error) {
+	if err := d.validate(); err != nil {
+		return nil, err
+	}
+
+	var resets []func() error
+	reset := func() error {
+		var errs []error
+		for i := len(resets) - 1; i >= 0; i-- {
+			errs = append(errs, resets[i]())
+		}
+		return errors.Join(errs...)
+	}
+
+	if d.Width > 0 {
+		if err := page.SetViewport(d.metrics()); err != nil {
+			return nil, errors.Join(err, reset())
+		}
+		resets = append(resets, func() error {
+			return page.SetViewport(baseline.MetricsEmulation())
+		})
+	}
+
+	if d.Touch {
+		if err := (proto.EmulationSetTouchEmulationEnabled{Enabled: true, MaxTouchPoints: intPtr(5)}).Call(page); err != nil {
+			return nil, errors.Join(err, reset())
+		}
+		resets = append(resets, func() error {
+			return baseline.TouchEmulation().Call(page)
+		})
+	}
+
+	if media := d.emulatedMedia(); media.Media != "" || len(media.Features) > 0 {
+		if err := media.Call(page); err != nil {
+			return nil, errors.Join(err, reset())
+		}
+		resets = append(resets, func() error {
+			return proto.EmulationSetEmulatedMedia{}.Call(page)
+		})
+	}
+
+	return reset, nil
+}
+
+func intPtr(v int) *int {
+	return &v
+}
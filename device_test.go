@@ -0,0 +1,111 @@
+package pageviewer
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeviceByNameAndLandscape(t *testing.T) {
+	device, err := DeviceByName("IPHONE-15")
+	require.NoError(t, err)
+	assert.Equal(t, DeviceIPhone15, device)
+
+	landscape := device.Landscape()
+	assert.Equal(t, "iphone-15-landscape", landscape.Name)
+	assert.Equal(t, device.Height, landscape.Width)
+	assert.Equal(t, device.Width, landscape.Height)
+
+	_, err = DeviceByName("nokia-3310")
+	assert.Error(t, err)
+
+	for _, device := range DeviceProfiles() {
+		assert.NoError(t, device.validate(), device.Name)
+	}
+}
+
+func TestDeviceProfileValidate(t *testing.T) {
+	assert.Error(t, DeviceProfile{Width: 100}.validate())
+	assert.Error(t, DeviceProfile{DeviceScaleFactor: -1}.validate())
+	assert.Error(t, DeviceProfile{Media: "tv"}.validate())
+	assert.Error(t, DeviceProfile{ColorScheme: "sepia"}.validate())
+	assert.Error(t, DeviceProfile{ReducedMotion: "none"}.validate())
+	assert.NoError(t, DeviceProfile{Media: "print", ColorScheme: "dark", ReducedMotion: "reduce"}.validate())
+}
+
+func TestDeviceProfileEmulatedMedia(t *testing.T) {
+	media := DeviceProfile{Media: "print", ColorScheme: "dark", ReducedMotion: "reduce"}.emulatedMedia()
+	assert.Equal(t, "print", media.Media)
+	assert.Equal(t, []*proto.EmulationMediaFeature{
+		{Name: "prefers-color-scheme", Value: "dark"},
+		{Name: "prefers-reduced-motion", Value: "reduce"},
+	}, media.Features)
+	assert.Equal(t, 1.0, DeviceProfile{Width: 10, Height: 10}.metrics().DeviceScaleFactor)
+}
+
+type deviceProbe struct {
+	Width       int     `json:"width"`
+	DPR         float64 `json:"dpr"`
+	TouchPoints int     `json:"touch_points"`
+	UserAgent   string  `json:"user_agent"`
+	Dark        bool    `json:"dark"`
+	Print       bool    `json:"print"`
+}
+
+func TestClientDeviceEmulationIsResetOnRelease(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(`<html><head><meta name="viewport" content="width=device-width"><title>device</title></head><body>device</body></html>`))
+	}))
+	defer s.Close()
+
+	client := newTestClient(t, Config{PoolSize: 1, Warmup: 1})
+
+	probe := func(opts ...RequestOption) deviceProbe {
+		var result deviceProbe
+		err := client.Visit(context.Background(), s.URL, func(page *rod.Page) error {
+			r, err := page.Eval(`() => JSON.stringify({
+				width: window.innerWidth,
+				dpr: window.devicePixelRatio,
+				touch_points: navigator.maxTouchPoints,
+				user_agent: navigator.userAgent,
+				dark: matchMedia('(prefers-color-scheme: dark)').matches,
+				print: matchMedia('print').matches,
+			})`)
+			if err != nil {
+				return err
+			}
+			return json.Unmarshal([]byte(r.Value.Str()), &result)
+		}, opts...)
+		require.NoError(t, err)
+		return result
+	}
+
+	device := DeviceIPhone15
+	device.ColorScheme = "dark"
+	device.Media = "print"
+	emulated := probe(WithDevice(device))
+	assert.Equal(t, 393, emulated.Width)
+	assert.Equal(t, 3.0, emulated.DPR)
+	assert.Equal(t, 5, emulated.TouchPoints)
+	assert.Contains(t, emulated.UserAgent, "iPhone")
+	assert.True(t, emulated.Dark)
+	assert.True(t, emulated.Print)
+
+	reset := probe()
+	assert.NotEqual(t, 393, reset.Width)
+	assert.NotContains(t, reset.UserAgent, "iPhone")
+	assert.False(t, reset.Dark)
+	assert.False(t, reset.Print)
+
+	override := probe(WithDevice(DevicePixel8), WithUserAgent("pageviewer-test/1.0"))
+	assert.Equal(t, 412, override.Width)
+	assert.Equal(t, "pageviewer-test/1.0", override.UserAgent)
+}
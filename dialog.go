@@ -0,0 +1,124 @@
+package pageviewer
+
+import (
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// DialogAction JS 对话框的处理方式
+type DialogAction string
+
+const (
+	DialogAccept  DialogAction = "accept"  // 点击确定，prompt 返回 PromptText
+	DialogDismiss DialogAction = "dismiss" // 点击取消，confirm 返回 false，prompt 返回 null
+)
+
+// DialogPolicy alert / confirm / prompt / beforeunload 对话框的自动处理策略，零值表示全部接受
+type DialogPolicy struct {
+	Action     DialogAction
+	PromptText string // 接受 prompt 时填入的文本，为空时使用页面提供的默认值
+}
+
+// DialogRecord 请求期间弹出的对话框，记录在 TraceAttempt.Dialogs 中
+type DialogRecord struct {
+	Type    string // alert、confirm、prompt、beforeunload
+	Message string
+	URL     string
+	Action  DialogAction
+	At      time.Time
+}
+
+// IsZero 判断是否为未设置的零值
+func (p DialogPolicy) IsZero() bool {
+	return p == DialogPolicy{}
+}
+
+func (p DialogPolicy) or(fallback DialogPolicy) DialogPolicy {
+	if p.IsZero() {
+		return fallback
+	}
+	return p
+}
+
+// handle 返回对话框的处理命令
+func (p DialogPolicy) handle(e *proto.PageJavascriptDialogOpening) proto.PageHandleJavaScriptDialog {
+	cmd := proto.PageHandleJavaScriptDialog{Accept: p.Action != DialogDismiss}
+	if cmd.Accept && e.Type == proto.PageDialogTypePrompt {
+		cmd.PromptText = p.PromptText
+		if cmd.PromptText == "" {
+			cmd.PromptText = e.DefaultPrompt
+		}
+	}
+	return cmd
+}
+
+func (p DialogPolicy) action() DialogAction {
+	if p.Action == DialogDismiss {
+		return DialogDismiss
+	}
+	return DialogAccept
+}
+
+// dialogHandler 在 worker 整个生命周期内监听 Page.javascriptDialogOpening，避免对话框卡住页面；
+// 请求期间按请求的策略处理并收集记录，空闲时直接接受
+type dialogHandler struct {
+	mu      sync.Mutex
+	policy  DialogPolicy
+	active  bool
+	records []DialogRecord
+	stop    func()
+}
+
+func newDialogHandler(page *rod.Page) *dialogHandler {
+	h := &dialogHandler{}
+	listenPage, cancel := page.WithCancel()
+	h.stop = cancel
+
+	wait := listenPage.EachEvent(func(e *proto.PageJavascriptDialogOpening) {
+		h.mu.Lock()
+		policy := h.policy
+		if h.active {
+			h.records = append(h.records, DialogRecord{
+				Type:    string(e.Type),
+				Message: e.Message,
+				URL:     e.URL,
+				Action:  policy.action(),
+				At:      time.Now(),
+			})
+		}
+		h.mu.Unlock()
+
+		_ = policy.handle(e).Call(listenPage)
+	})
+	go wait()
+	return h
+}
+
+// begin 设置本次请求的策略，返回结束请求的函数，结束时恢复空闲状态并返回请求期间的对话框记录
+func (h *dialogHandler) begin(policy DialogPolicy) func() []DialogRecord {
+	if h == nil {
+		return func() []DialogRecord { return nil }
+	}
+
+	h.mu.Lock()
+	h.policy, h.active, h.records = policy, true, nil
+	h.mu.Unlock()
+
+	return func() []DialogRecord {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		records := h.records
+		h.policy, h.active, h.records = DialogPolicy{}, false, nil
+		return records
+	}
+}
+
+func (h *dialogHandler) close() {
+	if h == nil || h.stop == nil {
+		return
+	}
+	h.stop()
+}
@@ -0,0 +1,73 @@
+package pageviewer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDialogPolicyHandle(t *testing.T) {
+	prompt := &proto.PageJavascriptDialogOpening{Type: proto.PageDialogTypePrompt, DefaultPrompt: "default"}
+
+	cmd := DialogPolicy{}.handle(prompt)
+	assert.True(t, cmd.Accept)
+	assert.Equal(t, "default", cmd.PromptText)
+
+	cmd = DialogPolicy{Action: DialogAccept, PromptText: "custom"}.handle(prompt)
+	assert.Equal(t, "custom", cmd.PromptText)
+
+	cmd = DialogPolicy{Action: DialogDismiss, PromptText: "custom"}.handle(prompt)
+	assert.False(t, cmd.Accept)
+	assert.Empty(t, cmd.PromptText)
+
+	cmd = DialogPolicy{PromptText: "custom"}.handle(&proto.PageJavascriptDialogOpening{Type: proto.PageDialogTypeAlert})
+	assert.True(t, cmd.Accept)
+	assert.Empty(t, cmd.PromptText)
+}
+
+func TestDialogHandlerNilSafe(t *testing.T) {
+	var h *dialogHandler
+	assert.Nil(t, h.begin(DialogPolicy{})())
+	h.close()
+}
+
+func TestClientHandlesJavaScriptDialogs(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(`<html><head><title>dialogs</title></head><body><p id="out"></p><script>
+alert('hello');
+const confirmed = confirm('continue?');
+const answer = prompt('name?', 'guest');
+document.getElementById('out').textContent = 'confirmed=' + confirmed + ' answer=' + answer;
+</script></body></html>`))
+	}))
+	defer s.Close()
+
+	client := newTestClient(t, Config{PoolSize: 1, Warmup: 1})
+
+	html, err := client.HTML(context.Background(), s.URL, WithTraceID("dialogs-accept"), WithDialogPolicy(DialogPolicy{Action: DialogAccept, PromptText: "pageviewer"}))
+	require.NoError(t, err)
+	assert.Contains(t, html, "confirmed=true answer=pageviewer")
+
+	trace, ok := client.DebugTrace("dialogs-accept")
+	require.True(t, ok)
+	require.Len(t, trace.Dialogs, 3)
+	assert.Equal(t, "alert", trace.Dialogs[0].Type)
+	assert.Equal(t, "hello", trace.Dialogs[0].Message)
+	assert.Equal(t, "prompt", trace.Dialogs[2].Type)
+	assert.Equal(t, DialogAccept, trace.Dialogs[2].Action)
+
+	html, err = client.HTML(context.Background(), s.URL, WithTraceID("dialogs-dismiss"), WithDialogPolicy(DialogPolicy{Action: DialogDismiss}))
+	require.NoError(t, err)
+	assert.Contains(t, html, "confirmed=false answer=null")
+
+	trace, ok = client.DebugTrace("dialogs-dismiss")
+	require.True(t, ok)
+	require.Len(t, trace.Dialogs, 3)
+	assert.Equal(t, DialogDismiss, trace.Dialogs[1].Action)
+}
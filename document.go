@@ -0,0 +1,221 @@
+package pageviewer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	htmltomarkdown "github.com/JohannesKaufmann/html-to-markdown/v2"
+	"github.com/JohannesKaufmann/html-to-markdown/v2/converter"
+	"github.com/ledongthuc/pdf"
+)
+
+const documentExcerptLength = 200
+
+// Document PDF 主文档的抽取结果。嵌入的 ReadabilityArticleWithMarkdown 与 ReadabilityArticle 的字段含义一致：
+// Title / Byline 取自 PDF 元数据，Content / HTML 为按页生成的 HTML，TextContent / Markdown 为全文，
+// 便于下游正文处理流程直接复用
+type Document struct {
+	ReadabilityArticleWithMarkdown
+	ContentType string            `json:"content_type"`
+	FinalURL    string            `json:"final_url"`
+	Pages       []DocumentPage    `json:"pages"`
+	Metadata    DocumentMetadata  `json:"metadata"`
+	Outline     []DocumentOutline `json:"outline"`
+}
+
+// DocumentPage 单页文本，Number 从 1 开始
+type DocumentPage struct {
+	Number int    `json:"number"`
+	Text   string `json:"text"`
+}
+
+// DocumentMetadata PDF 文档信息字典，日期为 RFC 3339 格式，无法解析时保留原始值
+type DocumentMetadata struct {
+	Title        string `json:"title,omitempty"`
+	Author       string `json:"author,omitempty"`
+	Subject      string `json:"subject,omitempty"`
+	Keywords     string `json:"keywords,omitempty"`
+	Creator      string `json:"creator,omitempty"`
+	Producer     string `json:"producer,omitempty"`
+	CreationDate string `json:"creation_date,omitempty"`
+	ModDate      string `json:"mod_date,omitempty"`
+}
+
+// DocumentOutline PDF 书签目录
+type DocumentOutline struct {
+	Title    string            `json:"title"`
+	Children []DocumentOutline `json:"children,omitempty"`
+}
+
+// Document 下载 PDF 主文档并抽取每页文本、元数据和书签目录，主文档不是 PDF 时返回 ErrUnsupportedContentType
+func (c *Client) Document(ctx context.Context, url string, opts ...RequestOption) (Document, error) {
+	opts = append(opts[:len(opts):len(opts)], WithAllowedContentTypes("application/pdf"))
+	resp, err := c.RawBytes(ctx, url, opts...)
+	if err != nil {
+		return Document{}, err
+	}
+
+	doc, err := parsePDFDocument(resp.Body, resp.FinalURL)
+	if err != nil {
+		return Document{}, err
+	}
+	doc.ContentType = resp.ContentType
+	doc.FinalURL = resp.FinalURL
+	return doc, nil
+}
+
+// parsePDFDocument 解析 PDF 字节，PDF 解析库遇到损坏的文件会 panic，这里统一转成错误
+func parsePDFDocument(data []byte, url string) (doc Document, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("pageviewer: invalid pdf: %v", r)
+		}
+	}()
+
+	reader, err := pdf.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return Document{}, fmt.Errorf("pageviewer: invalid pdf: %w", err)
+	}
+
+	doc.Metadata = newDocumentMetadata(reader.Trailer().Key("Info"))
+	doc.Outline = newDocumentOutline(reader.Outline().Child)
+	doc.Pages = make([]DocumentPage, 0, reader.NumPage())
+	for i := 1; i <= reader.NumPage(); i++ {
+		page := reader.Page(i)
+		if page.V.IsNull() {
+			continue
+		}
+		text, err := page.GetPlainText(nil)
+		if err != nil {
+			return Document{}, fmt.Errorf("pageviewer: invalid pdf page %d: %w", i, err)
+		}
+		doc.Pages = append(doc.Pages, DocumentPage{Number: i, Text: strings.TrimSpace(text)})
+	}
+
+	if err := fillDocumentArticle(&doc, url); err != nil {
+		return Document{}, err
+	}
+	return doc, nil
+}
+
+func newDocumentMetadata(info pdf.Value) DocumentMetadata {
+	text := func(key string) string {
+		return strings.TrimSpace(info.Key(key).Text())
+	}
+	return DocumentMetadata{
+		Title:        text("Title"),
+		Author:       text("Author"),
+		Subject:      text("Subject"),
+		Keywords:     text("Keywords"),
+		Creator:      text("Creator"),
+		Producer:     text("Producer"),
+		CreationDate: parsePDFDate(text("CreationDate")),
+		ModDate:      parsePDFDate(text("ModDate")),
+	}
+}
+
+func newDocumentOutline(items []pdf.Outline) []DocumentOutline {
+	outline := make([]DocumentOutline, 0, len(items))
+	for _, item := range items {
+		outline = append(outline, DocumentOutline{
+			Title:    strings.TrimSpace(item.Title),
+			Children: newDocumentOutline(item.Child),
+		})
+	}
+	return outline
+}
+
+// parsePDFDate 把 D:YYYYMMDDHHmmSSOHH'mm' 格式的 PDF 日期转成 RFC 3339，省略的部分按规范取默认值
+func parsePDFDate(value string) string {
+	raw := strings.TrimPrefix(value, "D:")
+	if raw == "" {
+		return value
+	}
+
+	digits := len(raw)
+	for i, r := range raw {
+		if r < '0' || r > '9' {
+			digits = i
+			break
+		}
+	}
+	layouts := map[int]string{4: "2006", 6: "200601", 8: "20060102", 10: "2006010215", 12: "200601021504", 14: "20060102150405"}
+	layout, ok := layouts[digits]
+	if !ok {
+		return value
+	}
+
+	loc := time.UTC
+	if zone := strings.TrimSuffix(strings.ReplaceAll(raw[digits:], "'", ":"), ":"); zone != "" && !strings.HasPrefix(zone, "Z") {
+		if len(zone) == 3 {
+			zone += ":00"
+		}
+		offset, err := time.Parse("-07:00", zone)
+		if err != nil {
+			return value
+		}
+		_, seconds := offset.Zone()
+		loc = time.FixedZone("", seconds)
+	}
+
+	t, err := time.ParseInLocation(layout, raw[:digits], loc)
+	if err != nil {
+		return value
+	}
+	return t.Format(time.RFC3339)
+}
+
+// fillDocumentArticle 按 ReadabilityArticle 的字段约定填充正文，每页生成一个 section，每行一个段落
+func fillDocumentArticle(doc *Document, url string) error {
+	var content strings.Builder
+	texts := make([]string, 0, len(doc.Pages))
+	content.WriteString("<article>")
+	for _, page := range doc.Pages {
+		fmt.Fprintf(&content, `<section data-page="%d">`, page.Number)
+		for _, line := range strings.Split(page.Text, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				content.WriteString("<p>" + html.EscapeString(line) + "</p>")
+			}
+		}
+		content.WriteString("</section>")
+		if page.Text != "" {
+			texts = append(texts, page.Text)
+		}
+	}
+	content.WriteString("</article>")
+
+	article := &doc.ReadabilityArticleWithMarkdown
+	article.Title = doc.Metadata.Title
+	article.Byline = doc.Metadata.Author
+	article.PublishedTime = doc.Metadata.CreationDate
+	article.Content = content.String()
+	article.HTML = article.Content
+	article.TextContent = strings.Join(texts, "\n\n")
+	article.Length = utf8.RuneCountInString(article.TextContent)
+	article.Excerpt = doc.Metadata.Subject
+	if article.Excerpt == "" {
+		article.Excerpt = documentExcerpt(article.TextContent)
+	}
+
+	var err error
+	article.Markdown, err = htmltomarkdown.ConvertString(article.Content, converter.WithDomain(url))
+	return err
+}
+
+// documentExcerpt 取第一行非空文本作为摘要，过长时按字符截断
+func documentExcerpt(text string) string {
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			if runes := []rune(line); len(runes) > documentExcerptLength {
+				return string(runes[:documentExcerptLength])
+			}
+			return line
+		}
+	}
+	return ""
+}
@@ -0,0 +1,76 @@
+package pageviewer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePDFDate(t *testing.T) {
+	assert.Equal(t, "2025-04-10T10:32:36Z", parsePDFDate("D:20250410103236+00'00'"))
+	assert.Equal(t, "2025-04-10T10:32:36+08:00", parsePDFDate("D:20250410103236+08'00'"))
+	assert.Equal(t, "2025-04-10T10:32:36-05:30", parsePDFDate("D:20250410103236-05'30"))
+	assert.Equal(t, "2025-04-10T10:32:36+08:00", parsePDFDate("D:20250410103236+08"))
+	assert.Equal(t, "2025-04-01T00:00:00Z", parsePDFDate("D:202504"))
+	assert.Equal(t, "2025-04-10T10:32:36Z", parsePDFDate("20250410103236Z"))
+	assert.Equal(t, "yesterday", parsePDFDate("yesterday"))
+	assert.Empty(t, parsePDFDate(""))
+}
+
+func TestParsePDFDocumentExtractsPagesAndMetadata(t *testing.T) {
+	data, err := os.ReadFile("test/aaa.pdf")
+	require.NoError(t, err)
+
+	doc, err := parsePDFDocument(data, "https://example.com/aaa.pdf")
+	require.NoError(t, err)
+	require.Len(t, doc.Pages, 1)
+	assert.Equal(t, DocumentPage{Number: 1, Text: "MMM"}, doc.Pages[0])
+	assert.Equal(t, "Skia/PDF m100", doc.Metadata.Producer)
+	assert.Equal(t, "2025-04-10T10:32:36Z", doc.Metadata.CreationDate)
+	assert.Equal(t, doc.Metadata.CreationDate, doc.PublishedTime)
+	assert.Empty(t, doc.Outline)
+	assert.NotNil(t, doc.Outline)
+
+	assert.Equal(t, `<article><section data-page="1"><p>MMM</p></section></article>`, doc.Content)
+	assert.Equal(t, doc.Content, doc.HTML)
+	assert.Equal(t, "MMM", doc.TextContent)
+	assert.Equal(t, 3, doc.Length)
+	assert.Equal(t, "MMM", doc.Excerpt)
+	assert.Equal(t, "MMM", doc.Markdown)
+
+	_, err = parsePDFDocument([]byte("%PDF-1.7 broken"), "")
+	assert.ErrorContains(t, err, "invalid pdf")
+}
+
+func TestClientDocumentExtractsPDF(t *testing.T) {
+	pdf, err := os.ReadFile("test/aaa.pdf")
+	require.NoError(t, err)
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/aaa.pdf" {
+			w.Header().Set("Content-Type", "application/pdf")
+			_, _ = w.Write(pdf)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(`<html><body>not a pdf</body></html>`))
+	}))
+	defer s.Close()
+
+	client := newTestClient(t, Config{PoolSize: 1, Warmup: 1})
+
+	doc, err := client.Document(context.Background(), s.URL+"/aaa.pdf")
+	require.NoError(t, err)
+	assert.Equal(t, "application/pdf", doc.ContentType)
+	assert.Equal(t, s.URL+"/aaa.pdf", doc.FinalURL)
+	require.Len(t, doc.Pages, 1)
+	assert.Equal(t, "MMM", doc.TextContent)
+
+	_, err = client.Document(context.Background(), s.URL)
+	assert.ErrorIs(t, err, ErrUnsupportedContentType)
+}
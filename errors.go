@@ -9,4 +9,7 @@ var (
 	ErrNavigationFailed       = errors.New("pageviewer: navigation failed")
 	ErrUnsupportedContentType = errors.New("pageviewer: unsupported content type")
 	ErrWorkerBroken           = errors.New("pageviewer: worker broken")
+	ErrElementNotFound        = errors.New("pageviewer: element not found")
+	ErrBodyTooLarge           = errors.New("pageviewer: response body too large")
+	ErrWaitTimeout            = errors.New("pageviewer: wait condition timeout")
 )
@@ -0,0 +1,209 @@
+package pageviewer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// SelectorType 选择器类型
+type SelectorType string
+
+const (
+	SelectorCSS   SelectorType = "css"   // 默认
+	SelectorXPath SelectorType = "xpath" // 相对父元素查询时需要以 . 开头，例如 .//a
+)
+
+// ExtractTarget 字段取值方式
+type ExtractTarget string
+
+const (
+	ExtractText      ExtractTarget = "text"       // 默认，元素可见文本，首尾空白会被去掉
+	ExtractHTML      ExtractTarget = "html"       // innerHTML
+	ExtractOuterHTML ExtractTarget = "outer_html" // outerHTML
+	ExtractAttribute ExtractTarget = "attribute"  // Attribute 指定的属性值
+)
+
+// Schema 声明式抽取规则，字段在整个文档范围内求值
+type Schema struct {
+	Fields []SchemaField `json:"fields"`
+}
+
+// SchemaField 一个命名字段。Fields 非空时为嵌套对象，选择器定位到的元素作为子字段的查询范围；
+// Selector 为空时直接使用父元素。未匹配到元素时标量字段为 nil，List 字段为空数组
+type SchemaField struct {
+	Name      string        `json:"name"`
+	Selector  string        `json:"selector,omitempty"`
+	Type      SelectorType  `json:"type,omitempty"`
+	Target    ExtractTarget `json:"target,omitempty"`
+	Attribute string        `json:"attribute,omitempty"`
+	List      bool          `json:"list,omitempty"`
+	Fields    []SchemaField `json:"fields,omitempty"`
+}
+
+// ParseSchema 从 JSON 解析并校验抽取规则，未知字段会报错以便尽早发现拼写错误
+func ParseSchema(data []byte) (Schema, error) {
+	var schema Schema
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&schema); err != nil {
+		return Schema{}, fmt.Errorf("pageviewer: invalid schema: %w", err)
+	}
+	if err := schema.Validate(); err != nil {
+		return Schema{}, err
+	}
+	return schema, nil
+}
+
+// Validate 校验字段名、选择器类型和取值方式
+func (s Schema) Validate() error {
+	if len(s.Fields) == 0 {
+		return fmt.Errorf("pageviewer: invalid schema: no fields")
+	}
+	return validateSchemaFields(s.Fields, "")
+}
+
+func validateSchemaFields(fields []SchemaField, parent string) error {
+	seen := make(map[string]struct{}, len(fields))
+	for _, field := range fields {
+		path := parent + field.Name
+		if field.Name == "" {
+			return fmt.Errorf("pageviewer: invalid schema field %q: empty name", parent)
+		}
+		if _, ok := seen[field.Name]; ok {
+			return fmt.Errorf("pageviewer: invalid schema field %q: duplicate name", path)
+		}
+		seen[field.Name] = struct{}{}
+
+		switch field.Type {
+		case "", SelectorCSS, SelectorXPath:
+		default:
+			return fmt.Errorf("pageviewer: invalid schema field %q: unsupported selector type %q", path, field.Type)
+		}
+		if field.Type == SelectorXPath && field.Selector == "" {
+			return fmt.Errorf("pageviewer: invalid schema field %q: xpath selector is empty", path)
+		}
+
+		if len(field.Fields) > 0 {
+			if field.Target != "" || field.Attribute != "" {
+				return fmt.Errorf("pageviewer: invalid schema field %q: nested fields cannot set target or attribute", path)
+			}
+			if err := validateSchemaFields(field.Fields, path+"."); err != nil {
+				return err
+			}
+			continue
+		}
+
+		switch field.Target {
+		case "", ExtractText, ExtractHTML, ExtractOuterHTML:
+			if field.Attribute != "" {
+				return fmt.Errorf("pageviewer: invalid schema field %q: attribute requires target %q", path, ExtractAttribute)
+			}
+		case ExtractAttribute:
+			if field.Attribute == "" {
+				return fmt.Errorf("pageviewer: invalid schema field %q: attribute is empty", path)
+			}
+		default:
+			return fmt.Errorf("pageviewer: invalid schema field %q: unsupported target %q", path, field.Target)
+		}
+	}
+	return nil
+}
+
+// Extract 在页面稳定后按 Schema 抽取数据
+func (c *Client) Extract(ctx context.Context, url string, schema Schema, opts ...RequestOption) (map[string]any, error) {
+	if err := schema.Validate(); err != nil {
+		return nil, err
+	}
+
+	var result map[string]any
+	err := c.visitWithOptions(ctx, url, NewRequestOptions(opts...), true, func(page *rod.Page, _ *proto.NetworkResponseReceived) error {
+		var err error
+		result, err = extractSchema(page, schema)
+		return err
+	})
+	return result, err
+}
+
+// ExtractAs 按 Schema 抽取后解码到 T，字段名对应 T 的 json tag
+func ExtractAs[T any](ctx context.Context, c *Client, url string, schema Schema, opts ...RequestOption) (T, error) {
+	var value T
+	result, err := c.Extract(ctx, url, schema, opts...)
+	if err != nil {
+		return value, err
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return value, err
+	}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return value, err
+	}
+	return value, nil
+}
+
+func extractSchema(page *rod.Page, schema Schema) (map[string]any, error) {
+	r, err := page.Eval(`(fields) => {
+		const query = (scope, field) => {
+			if (!field.selector) {
+				return [scope];
+			}
+			if (field.type === 'xpath') {
+				const snapshot = document.evaluate(field.selector, scope, null, XPathResult.ORDERED_NODE_SNAPSHOT_TYPE, null);
+				const nodes = [];
+				for (let i = 0; i < snapshot.snapshotLength; i++) {
+					nodes.push(snapshot.snapshotItem(i));
+				}
+				return nodes;
+			}
+			const root = scope === document ? document : scope;
+			return Array.from(root.querySelectorAll(field.selector));
+		};
+		const value = (node, field) => {
+			if (node === document) {
+				node = document.documentElement;
+			}
+			if (node.nodeType !== Node.ELEMENT_NODE) {
+				return (node.textContent || '').trim();
+			}
+			switch (field.target) {
+			case 'html':
+				return node.innerHTML;
+			case 'outer_html':
+				return node.outerHTML;
+			case 'attribute':
+				return node.getAttribute(field.attribute);
+			default:
+				return ((node.innerText !== undefined ? node.innerText : node.textContent) || '').trim();
+			}
+		};
+		const object = (scope, fields) => {
+			const result = {};
+			for (const field of fields) {
+				const nodes = query(scope, field);
+				const extract = (node) => (field.fields && field.fields.length ? object(node, field.fields) : value(node, field));
+				if (field.list) {
+					result[field.name] = nodes.map(extract);
+				} else {
+					result[field.name] = nodes.length ? extract(nodes[0]) : null;
+				}
+			}
+			return result;
+		};
+		return object(document, fields);
+	}`, schema.Fields)
+	if err != nil {
+		return nil, err
+	}
+
+	var result map[string]any
+	if err := r.Value.Unmarshal(&result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,90 @@
+package pageviewer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSchemaValidatesFields(t *testing.T) {
+	schema, err := ParseSchema([]byte(`{"fields": [
+		{"name": "title", "selector": "h1"},
+		{"name": "items", "selector": "li", "list": true, "fields": [
+			{"name": "href", "selector": "a", "target": "attribute", "attribute": "href"}
+		]}
+	]}`))
+	require.NoError(t, err)
+	require.Len(t, schema.Fields, 2)
+	assert.Equal(t, "href", schema.Fields[1].Fields[0].Attribute)
+
+	tests := map[string]string{
+		`{"fields": []}`:                                                           "no fields",
+		`{"fields": [{"selector": "h1"}]}`:                                         "empty name",
+		`{"fields": [{"name": "a"}, {"name": "a"}]}`:                               `"a": duplicate name`,
+		`{"fields": [{"name": "a", "type": "regex"}]}`:                             "unsupported selector type",
+		`{"fields": [{"name": "a", "target": "attribute"}]}`:                       "attribute is empty",
+		`{"fields": [{"name": "a", "attribute": "href"}]}`:                         "attribute requires target",
+		`{"fields": [{"name": "a", "target": "json"}]}`:                            "unsupported target",
+		`{"fields": [{"name": "a", "target": "html", "fields": [{"name": "b"}]}]}`: "nested fields cannot set target",
+		`{"fields": [{"name": "a", "fields": [{"name": "b", "type": "xpath"}]}]}`:  `"a.b": xpath selector is empty`,
+		`{"fields": [{"name": "a", "selectr": "h1"}]}`:                             "unknown field",
+	}
+	for input, want := range tests {
+		_, err := ParseSchema([]byte(input))
+		assert.ErrorContains(t, err, want, input)
+	}
+}
+
+func TestClientExtractEvaluatesSchema(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(`<html><body>
+  <h1> Product list </h1>
+  <ul>
+    <li class="item"><a href="/a">Alpha</a><span class="price">1</span></li>
+    <li class="item"><a href="/b">Beta</a><span class="price">2</span></li>
+  </ul>
+  <div id="footer"><b>footer</b></div>
+</body></html>`))
+	}))
+	defer s.Close()
+
+	client := newTestClient(t, Config{PoolSize: 1, Warmup: 1})
+
+	schema := Schema{Fields: []SchemaField{
+		{Name: "title", Selector: "h1"},
+		{Name: "footer", Selector: "//div[@id='footer']", Type: SelectorXPath, Target: ExtractHTML},
+		{Name: "missing", Selector: ".missing"},
+		{Name: "items", Selector: "li.item", List: true, Fields: []SchemaField{
+			{Name: "name", Selector: "a"},
+			{Name: "href", Selector: ".//a", Type: SelectorXPath, Target: ExtractAttribute, Attribute: "href"},
+			{Name: "price", Selector: ".price"},
+		}},
+	}}
+
+	result, err := client.Extract(context.Background(), s.URL, schema)
+	require.NoError(t, err)
+	assert.Equal(t, "Product list", result["title"])
+	assert.Equal(t, "<b>footer</b>", result["footer"])
+	assert.Nil(t, result["missing"])
+	assert.Equal(t, []any{
+		map[string]any{"name": "Alpha", "href": "/a", "price": "1"},
+		map[string]any{"name": "Beta", "href": "/b", "price": "2"},
+	}, result["items"])
+
+	type item struct {
+		Name string `json:"name"`
+		Href string `json:"href"`
+	}
+	typed, err := ExtractAs[struct {
+		Title string `json:"title"`
+		Items []item `json:"items"`
+	}](context.Background(), client, s.URL, schema)
+	require.NoError(t, err)
+	assert.Equal(t, "Product list", typed.Title)
+	assert.Equal(t, []item{{Name: "Alpha", Href: "/a"}, {Name: "Beta", Href: "/b"}}, typed.Items)
+}
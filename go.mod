@@ -8,7 +8,11 @@ require (
 	github.com/JohannesKaufmann/html-to-markdown/v2 v2.4.0
 	github.com/go-rod/rod v0.116.2
 	github.com/go-rod/stealth v0.4.9
+	github.com/ledongthuc/pdf v0.0.0-20240201131950-da5b75280b06
 	github.com/stretchr/testify v1.10.0
+	github.com/ysmood/gson v0.7.3
+	golang.org/x/net v0.44.0
+	golang.org/x/text v0.29.0
 )
 
 require (
@@ -19,9 +23,7 @@ require (
 	github.com/ysmood/fetchup v0.3.0 // indirect
 	github.com/ysmood/goob v0.4.0 // indirect
 	github.com/ysmood/got v0.41.0 // indirect
-	github.com/ysmood/gson v0.7.3 // indirect
 	github.com/ysmood/leakless v0.9.0 // indirect
-	golang.org/x/net v0.44.0 // indirect
 	gopkg.in/check.v1 v1.0.0-20190902080502-41f04d3bba15 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
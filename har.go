@@ -0,0 +1,333 @@
+package pageviewer
+
+import (
+	"net/url"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod/lib/proto"
+)
+
+const (
+	harVersion = "1.2"
+	harPageID  = "page_1"
+	modulePath = "github.com/LubyRuffy/pageviewer"
+)
+
+// HAR HTTP Archive 1.2 文档，字段命名与规范保持一致，可直接 json.Marshal 后导入 DevTools 等工具
+type HAR struct {
+	Log HARLog `json:"log"`
+}
+
+type HARLog struct {
+	Version string     `json:"version"`
+	Creator HARCreator `json:"creator"`
+	Pages   []HARPage  `json:"pages"`
+	Entries []HAREntry `json:"entries"`
+}
+
+type HARCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type HARPage struct {
+	StartedDateTime time.Time      `json:"startedDateTime"`
+	ID              string         `json:"id"`
+	Title           string         `json:"title"`
+	PageTimings     HARPageTimings `json:"pageTimings"`
+}
+
+type HARPageTimings struct {
+	OnContentLoad float64 `json:"onContentLoad"`
+	OnLoad        float64 `json:"onLoad"`
+}
+
+type HAREntry struct {
+	PageRef         string      `json:"pageref"`
+	StartedDateTime time.Time   `json:"startedDateTime"`
+	Time            float64     `json:"time"` // 毫秒，等于 Timings 中非 -1 阶段之和（不含 ssl）
+	Request         HARRequest  `json:"request"`
+	Response        HARResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         HARTimings  `json:"timings"`
+	ServerIPAddress string      `json:"serverIPAddress,omitempty"`
+	ResourceType    string      `json:"_resourceType,omitempty"`
+	Error           string      `json:"_error,omitempty"` // 请求失败或被取消时的浏览器错误信息
+}
+
+type HARRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []HARCookie    `json:"cookies"`
+	Headers     []HARNameValue `json:"headers"`
+	QueryString []HARNameValue `json:"queryString"`
+	PostData    *HARPostData   `json:"postData,omitempty"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type HARResponse struct {
+	Status       int            `json:"status"`
+	StatusText   string         `json:"statusText"`
+	HTTPVersion  string         `json:"httpVersion"`
+	Cookies      []HARCookie    `json:"cookies"`
+	Headers      []HARNameValue `json:"headers"`
+	Content      HARContent     `json:"content"`
+	RedirectURL  string         `json:"redirectURL"`
+	HeadersSize  int            `json:"headersSize"`
+	BodySize     int            `json:"bodySize"`
+	TransferSize int            `json:"_transferSize"`
+}
+
+type HARCookie struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type HARNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type HARPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+type HARContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+}
+
+// HARTimings 各阶段耗时（毫秒），不适用的阶段为 -1
+type HARTimings struct {
+	Blocked float64 `json:"blocked"`
+	DNS     float64 `json:"dns"`
+	Connect float64 `json:"connect"`
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+	SSL     float64 `json:"ssl"`
+}
+
+// moduleVersion 返回构建信息中 pageviewer 模块的版本，本地开发构建没有版本号时返回空字符串
+func moduleVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	module := &info.Main
+	for _, dep := range info.Deps {
+		if dep.Path == modulePath {
+			module = dep
+			break
+		}
+	}
+	if module.Path != modulePath || module.Version == "(devel)" {
+		return ""
+	}
+	if module.Replace != nil {
+		return module.Replace.Version
+	}
+	return module.Version
+}
+
+// newHAR 把一次请求抓到的网络记录转换为 HAR，非 http(s) 请求和直到结束都没有响应也没有失败的请求会被忽略
+func newHAR(entries []*networkEntry, attempt TraceAttempt) *HAR {
+	har := &HAR{Log: HARLog{
+		Version: harVersion,
+		Creator: HARCreator{Name: "pageviewer", Version: moduleVersion()},
+		Pages: []HARPage{{
+			StartedDateTime: attempt.StartedAt,
+			ID:              harPageID,
+			Title:           attempt.URL,
+			PageTimings:     HARPageTimings{OnContentLoad: -1, OnLoad: -1},
+		}},
+		Entries: make([]HAREntry, 0, len(entries)),
+	}}
+
+	for _, entry := range entries {
+		if entry.Request == nil || !isHTTPURL(entry.Request.URL) {
+			continue
+		}
+		if entry.Response == nil && entry.ErrorText == "" {
+			continue
+		}
+		har.Log.Entries = append(har.Log.Entries, newHAREntry(entry))
+	}
+	return har
+}
+
+func newHAREntry(entry *networkEntry) HAREntry {
+	result := HAREntry{
+		PageRef:         harPageID,
+		StartedDateTime: entry.StartedAt,
+		ResourceType:    strings.ToLower(string(entry.ResourceType)),
+		Error:           entry.ErrorText,
+		Request:         newHARRequest(entry.Request),
+		Response: HARResponse{
+			Cookies:     []HARCookie{},
+			Headers:     []HARNameValue{},
+			RedirectURL: entry.RedirectURL,
+			HeadersSize: -1,
+			BodySize:    -1,
+		},
+	}
+
+	response := entry.Response
+	if response != nil {
+		httpVersion := harHTTPVersion(response.Protocol)
+		result.Request.HTTPVersion = httpVersion
+		result.ServerIPAddress = response.RemoteIPAddress
+		result.Response.Status = response.Status
+		result.Response.StatusText = response.StatusText
+		result.Response.HTTPVersion = httpVersion
+		result.Response.Headers = harHeaders(response.Headers)
+		result.Response.Content = HARContent{Size: entry.DataLength, MimeType: response.MIMEType}
+		if len(response.RequestHeaders) > 0 {
+			result.Request.Headers = harHeaders(response.RequestHeaders)
+		}
+	}
+	if entry.EncodedDataLength > 0 {
+		result.Response.TransferSize = int(entry.EncodedDataLength)
+		result.Response.BodySize = int(entry.EncodedDataLength)
+	}
+
+	result.Timings = harTimings(entry)
+	for _, phase := range []float64{
+		result.Timings.Blocked,
+		result.Timings.DNS,
+		result.Timings.Connect,
+		result.Timings.Send,
+		result.Timings.Wait,
+		result.Timings.Receive,
+	} {
+		if phase > 0 {
+			result.Time += phase
+		}
+	}
+	return result
+}
+
+func newHARRequest(request *proto.NetworkRequest) HARRequest {
+	result := HARRequest{
+		Method:      request.Method,
+		URL:         request.URL + request.URLFragment,
+		HTTPVersion: "HTTP/1.1",
+		Cookies:     []HARCookie{},
+		Headers:     harHeaders(request.Headers),
+		QueryString: []HARNameValue{},
+		HeadersSize: -1,
+		BodySize:    len(request.PostData),
+	}
+
+	if parsed, err := url.Parse(request.URL); err == nil {
+		for name, values := range parsed.Query() {
+			for _, value := range values {
+				result.QueryString = append(result.QueryString, HARNameValue{Name: name, Value: value})
+			}
+		}
+		sortHARNameValues(result.QueryString)
+	}
+
+	if request.HasPostData || request.PostData != "" {
+		result.PostData = &HARPostData{
+			MimeType: newHTTPHeader(request.Headers).Get("Content-Type"),
+			Text:     request.PostData,
+		}
+	}
+	return result
+}
+
+// harTimings 参照 DevTools 的导出逻辑把 ResourceTiming 拆分为 HAR 阶段；没有 timing 信息时（缓存、失败请求）整体计入 wait
+func harTimings(entry *networkEntry) HARTimings {
+	total := -1.0
+	if entry.EndTimestamp > 0 && entry.StartTimestamp > 0 {
+		total = float64(entry.EndTimestamp-entry.StartTimestamp) * 1000
+	}
+
+	var timing *proto.NetworkResourceTiming
+	if entry.Response != nil {
+		timing = entry.Response.Timing
+	}
+	if timing == nil {
+		return HARTimings{Blocked: -1, DNS: -1, Connect: -1, Send: 0, Wait: max(total, 0), Receive: 0, SSL: -1}
+	}
+
+	phase := func(start, end float64) float64 {
+		if start < 0 || end < 0 {
+			return -1
+		}
+		return end - start
+	}
+
+	blocked := -1.0
+	for _, start := range []float64{timing.DNSStart, timing.ConnectStart, timing.SendStart} {
+		if start >= 0 {
+			blocked = start
+			break
+		}
+	}
+	// blocked 从 requestWillBeSent 开始计算，包含排队时间
+	queued := (timing.RequestTime - float64(entry.StartTimestamp)) * 1000
+	if blocked >= 0 && queued > 0 {
+		blocked += queued
+	}
+
+	result := HARTimings{
+		Blocked: blocked,
+		DNS:     phase(timing.DNSStart, timing.DNSEnd),
+		Connect: phase(timing.ConnectStart, timing.ConnectEnd),
+		Send:    max(phase(timing.SendStart, timing.SendEnd), 0),
+		Wait:    max(phase(timing.SendEnd, timing.ReceiveHeadersEnd), 0),
+		Receive: 0,
+		SSL:     phase(timing.SslStart, timing.SslEnd),
+	}
+	if entry.EndTimestamp > 0 {
+		headersEnd := timing.RequestTime*1000 + timing.ReceiveHeadersEnd
+		result.Receive = max(float64(entry.EndTimestamp)*1000-headersEnd, 0)
+	}
+	return result
+}
+
+func harHeaders(headers proto.NetworkHeaders) []HARNameValue {
+	result := make([]HARNameValue, 0, len(headers))
+	for name, value := range headers {
+		if strings.HasPrefix(name, ":") {
+			continue
+		}
+		for _, v := range strings.Split(value.Str(), "\n") {
+			result = append(result, HARNameValue{Name: name, Value: strings.TrimSpace(v)})
+		}
+	}
+	sortHARNameValues(result)
+	return result
+}
+
+func sortHARNameValues(values []HARNameValue) {
+	sort.SliceStable(values, func(i, j int) bool {
+		if values[i].Name != values[j].Name {
+			return values[i].Name < values[j].Name
+		}
+		return values[i].Value < values[j].Value
+	})
+}
+
+func harHTTPVersion(protocol string) string {
+	switch strings.ToLower(protocol) {
+	case "", "http/1.1":
+		return "HTTP/1.1"
+	case "http/1.0":
+		return "HTTP/1.0"
+	case "h2":
+		return "HTTP/2"
+	case "h3", "quic":
+		return "HTTP/3"
+	default:
+		return protocol
+	}
+}
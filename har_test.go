@@ -0,0 +1,154 @@
+package pageviewer
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ysmood/gson"
+)
+
+func TestNewHARConvertsEntries(t *testing.T) {
+	startedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	entries := []*networkEntry{
+		{
+			ResourceType: proto.NetworkResourceTypeDocument,
+			Request: &proto.NetworkRequest{
+				URL:     "https://example.com/old?b=2&a=1",
+				Method:  "GET",
+				Headers: proto.NetworkHeaders{"Accept": gson.New("text/html")},
+			},
+			Response: &proto.NetworkResponse{
+				Status:     302,
+				StatusText: "Found",
+				Headers:    proto.NetworkHeaders{"Location": gson.New("/new")},
+			},
+			RedirectURL:    "https://example.com/new",
+			StartedAt:      startedAt,
+			StartTimestamp: 10,
+			EndTimestamp:   10.05,
+		},
+		{
+			ResourceType: proto.NetworkResourceTypeDocument,
+			Request:      &proto.NetworkRequest{URL: "https://example.com/new", Method: "GET"},
+			Response: &proto.NetworkResponse{
+				Status:          200,
+				StatusText:      "OK",
+				MIMEType:        "text/html",
+				Protocol:        "h2",
+				RemoteIPAddress: "127.0.0.1",
+				Headers:         proto.NetworkHeaders{"Set-Cookie": gson.New("a=1\nb=2")},
+				Timing: &proto.NetworkResourceTiming{
+					RequestTime:       10.1,
+					DNSStart:          1,
+					DNSEnd:            3,
+					ConnectStart:      3,
+					ConnectEnd:        10,
+					SslStart:          5,
+					SslEnd:            10,
+					SendStart:         10,
+					SendEnd:           11,
+					ReceiveHeadersEnd: 31,
+				},
+			},
+			StartedAt:         startedAt.Add(50 * time.Millisecond),
+			StartTimestamp:    10.1,
+			EndTimestamp:      10.141,
+			EncodedDataLength: 120,
+			DataLength:        512,
+		},
+		{
+			ResourceType: proto.NetworkResourceTypeScript,
+			Request:      &proto.NetworkRequest{URL: "https://example.com/app.js", Method: "GET"},
+			ErrorText:    "net::ERR_CONNECTION_REFUSED",
+		},
+		{
+			Request:  &proto.NetworkRequest{URL: "data:image/png;base64,AAAA", Method: "GET"},
+			Response: &proto.NetworkResponse{Status: 200},
+		},
+		{
+			Request: &proto.NetworkRequest{URL: "https://example.com/pending", Method: "GET"},
+		},
+	}
+
+	har := newHAR(entries, TraceAttempt{URL: "https://example.com/old", StartedAt: startedAt})
+	assert.Equal(t, "1.2", har.Log.Version)
+	// 测试构建中的模块没有版本号，creator.version 不能填 HAR 规范版本
+	assert.Equal(t, HARCreator{Name: "pageviewer"}, har.Log.Creator)
+	require.Len(t, har.Log.Pages, 1)
+	assert.Equal(t, "https://example.com/old", har.Log.Pages[0].Title)
+	require.Len(t, har.Log.Entries, 3)
+
+	redirect := har.Log.Entries[0]
+	assert.Equal(t, 302, redirect.Response.Status)
+	assert.Equal(t, "https://example.com/new", redirect.Response.RedirectURL)
+	assert.Equal(t, []HARNameValue{{Name: "a", Value: "1"}, {Name: "b", Value: "2"}}, redirect.Request.QueryString)
+	assert.InDelta(t, 50, redirect.Timings.Wait, 0.001)
+	assert.InDelta(t, 50, redirect.Time, 0.001)
+
+	document := har.Log.Entries[1]
+	assert.Equal(t, "HTTP/2", document.Response.HTTPVersion)
+	assert.Equal(t, "127.0.0.1", document.ServerIPAddress)
+	assert.Equal(t, "document", document.ResourceType)
+	assert.Equal(t, HARContent{Size: 512, MimeType: "text/html"}, document.Response.Content)
+	assert.Equal(t, 120, document.Response.TransferSize)
+	assert.Equal(t, []HARNameValue{{Name: "Set-Cookie", Value: "a=1"}, {Name: "Set-Cookie", Value: "b=2"}}, document.Response.Headers)
+	assert.InDelta(t, 1, document.Timings.Blocked, 0.001)
+	assert.InDelta(t, 2, document.Timings.DNS, 0.001)
+	assert.InDelta(t, 7, document.Timings.Connect, 0.001)
+	assert.InDelta(t, 5, document.Timings.SSL, 0.001)
+	assert.InDelta(t, 1, document.Timings.Send, 0.001)
+	assert.InDelta(t, 20, document.Timings.Wait, 0.001)
+	assert.InDelta(t, 10, document.Timings.Receive, 0.001)
+	assert.InDelta(t, 41, document.Time, 0.001)
+
+	failed := har.Log.Entries[2]
+	assert.Equal(t, 0, failed.Response.Status)
+	assert.Equal(t, "net::ERR_CONNECTION_REFUSED", failed.Error)
+
+	data, err := json.Marshal(har)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"cookies":[]`)
+	assert.Contains(t, string(data), `"_error":"net::ERR_CONNECTION_REFUSED"`)
+}
+
+func TestClientHTMLRecordsNetworkLogInTrace(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			http.Redirect(w, r, "/page", http.StatusFound)
+		case "/style.css":
+			w.Header().Set("Content-Type", "text/css")
+			_, _ = w.Write([]byte(`body { color: red; }`))
+		default:
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			_, _ = w.Write([]byte(`<html><head><link rel="stylesheet" href="/style.css"></head><body>network log</body></html>`))
+		}
+	}))
+	defer s.Close()
+
+	client := newTestClient(t, Config{PoolSize: 1, Warmup: 1})
+
+	var har HAR
+	_, err := client.HTML(context.Background(), s.URL+"/", WithNetworkLog(&har), WithTraceID("trace-har"))
+	require.NoError(t, err)
+
+	urls := make(map[string]int, len(har.Log.Entries))
+	for _, entry := range har.Log.Entries {
+		urls[entry.Request.URL] = entry.Response.Status
+	}
+	assert.Equal(t, http.StatusFound, urls[s.URL+"/"])
+	assert.Equal(t, http.StatusOK, urls[s.URL+"/page"])
+	assert.Equal(t, http.StatusOK, urls[s.URL+"/style.css"])
+
+	trace, ok := client.DebugTrace("trace-har")
+	require.True(t, ok)
+	require.NotNil(t, trace.NetworkLog)
+	assert.Len(t, trace.NetworkLog.Log.Entries, len(har.Log.Entries))
+}
@@ -0,0 +1,188 @@
+package pageviewer
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// LinkSource 链接来源元素
+type LinkSource string
+
+const (
+	LinkSourceAnchor LinkSource = "a"
+	LinkSourceArea   LinkSource = "area"
+	LinkSourceLink   LinkSource = "link"
+)
+
+// Link 页面中的一个链接
+type Link struct {
+	URL      string     `json:"url"`      // 按 document.baseURI 解析后的绝对地址
+	Text     string     `json:"text"`     // a 为可见文本，area 为 alt，link 为空；连续空白会被合并
+	Rel      string     `json:"rel"`      // 原始 rel 属性
+	Title    string     `json:"title"`    // title 属性
+	Target   string     `json:"target"`   // target 属性
+	NoFollow bool       `json:"nofollow"` // rel 中包含 nofollow
+	Internal bool       `json:"internal"` // 与最终页面 URL 同主机名的 http(s) 链接
+	Source   LinkSource `json:"source"`   // 来源元素：a / area / link
+	Position int        `json:"position"` // 在文档中 a / area / link 元素里的顺序，从 0 开始
+}
+
+// LinkListOptions 链接提取配置项
+type LinkListOptions struct {
+	Dedup         bool // 按 URL 去重，保留第一次出现的链接
+	StripFragment bool // 去掉 URL 中的 #fragment，和 Dedup 一起使用时同页锚点会被合并
+}
+
+type rawLinkList struct {
+	PageURL string    `json:"pageURL"`
+	Links   []rawLink `json:"links"`
+}
+
+type rawLink struct {
+	URL      string `json:"url"`
+	Text     string `json:"text"`
+	Rel      string `json:"rel"`
+	Title    string `json:"title"`
+	Target   string `json:"target"`
+	Source   string `json:"source"`
+	Position int    `json:"position"`
+}
+
+// LinkList 返回页面中 a / area / link 元素的结构化链接列表
+func (c *Client) LinkList(ctx context.Context, url string, lo LinkListOptions, opts ...RequestOption) ([]Link, error) {
+	var links []Link
+	err := c.visitWithOptions(ctx, url, NewRequestOptions(opts...), true, func(page *rod.Page, _ *proto.NetworkResponseReceived) error {
+		var err error
+		links, err = collectLinkList(page, lo)
+		return err
+	})
+	return links, err
+}
+
+func collectLinkList(page *rod.Page, lo LinkListOptions) ([]Link, error) {
+	raw, err := evalLinks(page)
+	if err != nil {
+		return nil, err
+	}
+	return filterLinkList(buildLinkList(raw.PageURL, raw.Links), lo), nil
+}
+
+// evalLinks 在页面中读取 a / area / link 元素的原始属性，文本保留 innerText 原样
+func evalLinks(page *rod.Page) (rawLinkList, error) {
+	r, err := page.Eval(`() => {
+		const links = [];
+		document.querySelectorAll('a[href], area[href], link[href]').forEach((el, position) => {
+			let url;
+			try {
+				url = new URL(el.getAttribute('href'), document.baseURI).href;
+			} catch (e) {
+				return;
+			}
+			const source = el.tagName.toLowerCase();
+			let text = '';
+			if (source === 'a') {
+				text = el.innerText !== undefined ? el.innerText : el.textContent;
+			} else if (source === 'area') {
+				text = el.getAttribute('alt') || '';
+			}
+			links.push({
+				url,
+				text: text || '',
+				rel: el.getAttribute('rel') || '',
+				title: el.getAttribute('title') || '',
+				target: el.getAttribute('target') || '',
+				source,
+				position,
+			});
+		});
+		return { pageURL: location.href, links };
+	}`)
+	if err != nil {
+		return rawLinkList{}, err
+	}
+
+	var raw rawLinkList
+	err = r.Value.Unmarshal(&raw)
+	return raw, err
+}
+
+func buildLinkList(pageURL string, raw []rawLink) []Link {
+	pageHost := ""
+	if parsed, err := url.Parse(pageURL); err == nil {
+		pageHost = strings.ToLower(parsed.Hostname())
+	}
+
+	links := make([]Link, 0, len(raw))
+	for _, item := range raw {
+		if strings.HasPrefix(strings.ToLower(item.URL), "javascript:") {
+			continue
+		}
+
+		link := Link{
+			URL:      item.URL,
+			Text:     strings.Join(strings.Fields(item.Text), " "),
+			Rel:      item.Rel,
+			Title:    item.Title,
+			Target:   item.Target,
+			Source:   LinkSource(item.Source),
+			Position: item.Position,
+		}
+		for _, rel := range strings.Fields(strings.ToLower(item.Rel)) {
+			if rel == "nofollow" {
+				link.NoFollow = true
+			}
+		}
+		if parsed, err := url.Parse(item.URL); err == nil {
+			link.Internal = (parsed.Scheme == "http" || parsed.Scheme == "https") &&
+				pageHost != "" && strings.ToLower(parsed.Hostname()) == pageHost
+		}
+		links = append(links, link)
+	}
+	return links
+}
+
+// filterLinkList 按配置去掉 fragment 并去重，返回新切片，不修改入参
+func filterLinkList(links []Link, lo LinkListOptions) []Link {
+	result := make([]Link, 0, len(links))
+	seen := make(map[string]struct{}, len(links))
+	for _, link := range links {
+		if lo.StripFragment {
+			if i := strings.IndexByte(link.URL, '#'); i >= 0 {
+				link.URL = link.URL[:i]
+			}
+		}
+		if lo.Dedup {
+			if _, ok := seen[link.URL]; ok {
+				continue
+			}
+			seen[link.URL] = struct{}{}
+		}
+		result = append(result, link)
+	}
+	return result
+}
+
+// formatLinks 把原始链接渲染为 Links 返回的每行一个 <a> 片段，只保留有文本的 a 元素；
+// href 和文本按原样拼接、不做转义，与 LinkList 出现之前的输出保持一致
+func formatLinks(links []rawLink) string {
+	lines := make([]string, 0, len(links))
+	for _, link := range links {
+		if LinkSource(link.Source) != LinkSourceAnchor || link.Text == "" || strings.HasPrefix(link.URL, "javascript:") {
+			continue
+		}
+		lines = append(lines, `<a href="`+link.URL+`">`+link.Text+`</a>`)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func collectLinks(page *rod.Page) (string, error) {
+	raw, err := evalLinks(page)
+	if err != nil {
+		return "", err
+	}
+	return formatLinks(raw.Links), nil
+}
@@ -0,0 +1,100 @@
+package pageviewer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildLinkListClassifiesLinks(t *testing.T) {
+	links := buildLinkList("https://Example.com/page", []rawLink{
+		{URL: "https://example.com/a#top", Text: "  first\n  link ", Rel: "NoFollow ugc", Source: "a", Position: 0},
+		{URL: "javascript:void(0)", Text: "js", Source: "a", Position: 1},
+		{URL: "https://other.com/", Text: "other", Target: "_blank", Source: "a", Position: 2},
+		{URL: "https://example.com/map", Text: "map area", Source: "area", Position: 3},
+		{URL: "mailto:a@example.com", Text: "mail", Source: "a", Position: 4},
+		{URL: "https://example.com/style.css", Rel: "stylesheet", Source: "link", Position: 5},
+	})
+
+	require.Len(t, links, 5)
+	assert.Equal(t, Link{
+		URL:      "https://example.com/a#top",
+		Text:     "first link",
+		Rel:      "NoFollow ugc",
+		NoFollow: true,
+		Internal: true,
+		Source:   LinkSourceAnchor,
+	}, links[0])
+	assert.False(t, links[1].Internal)
+	assert.Equal(t, "_blank", links[1].Target)
+	assert.Equal(t, 2, links[1].Position)
+	assert.Equal(t, LinkSourceArea, links[2].Source)
+	assert.False(t, links[3].Internal)
+	assert.Equal(t, LinkSourceLink, links[4].Source)
+}
+
+func TestFilterLinkListStripsFragmentsAndDedups(t *testing.T) {
+	links := []Link{
+		{URL: "https://example.com/a#one", Text: "one"},
+		{URL: "https://example.com/a#two", Text: "two"},
+		{URL: "https://example.com/b", Text: "b"},
+		{URL: "https://example.com/b", Text: "b again"},
+	}
+
+	assert.Len(t, filterLinkList(links, LinkListOptions{}), 4)
+	assert.Equal(t, []Link{
+		{URL: "https://example.com/a#one", Text: "one"},
+		{URL: "https://example.com/a#two", Text: "two"},
+		{URL: "https://example.com/b", Text: "b"},
+	}, filterLinkList(links, LinkListOptions{Dedup: true}))
+	assert.Equal(t, []Link{
+		{URL: "https://example.com/a", Text: "one"},
+		{URL: "https://example.com/b", Text: "b"},
+	}, filterLinkList(links, LinkListOptions{Dedup: true, StripFragment: true}))
+	assert.Equal(t, "https://example.com/a#one", links[0].URL)
+}
+
+func TestFormatLinksKeepsLegacyFormat(t *testing.T) {
+	got := formatLinks([]rawLink{
+		{URL: "https://example.com/?a=1&b=2", Text: "<b>bold</b>", Source: "a"},
+		{URL: "https://example.com/spaced", Text: " two\n lines ", Source: "a"},
+		{URL: "https://example.com/empty", Source: "a"},
+		{URL: "javascript:void(0)", Text: "js", Source: "a"},
+		{URL: "https://example.com/area", Text: "area", Source: "area"},
+		{URL: "https://example.com/style.css", Source: "link"},
+	})
+	assert.Equal(t, "<a href=\"https://example.com/?a=1&b=2\"><b>bold</b></a>\n<a href=\"https://example.com/spaced\"> two\n lines </a>", got)
+}
+
+func TestClientLinkListReturnsStructuredLinks(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(`<html>
+<head><link rel="canonical" href="/canonical"></head>
+<body>
+  <a href="/next#section" title="Next" target="_blank">next page</a>
+  <a href="/next#other" rel="nofollow">next again</a>
+  <a href="https://external.example/">external</a>
+  <img usemap="#m" src="data:image/gif;base64,R0lGODlhAQABAAAAACw="><map name="m"><area href="/area" alt="area link" shape="rect" coords="0,0,1,1"></map>
+</body>
+</html>`))
+	}))
+	defer s.Close()
+
+	client := newTestClient(t, Config{PoolSize: 1, Warmup: 1})
+
+	links, err := client.LinkList(context.Background(), s.URL, LinkListOptions{Dedup: true, StripFragment: true})
+	require.NoError(t, err)
+	require.Len(t, links, 4)
+
+	assert.Equal(t, Link{URL: s.URL + "/canonical", Rel: "canonical", Internal: true, Source: LinkSourceLink, Position: 0}, links[0])
+	assert.Equal(t, Link{URL: s.URL + "/next", Text: "next page", Title: "Next", Target: "_blank", Internal: true, Source: LinkSourceAnchor, Position: 1}, links[1])
+	assert.Equal(t, "https://external.example/", links[2].URL)
+	assert.False(t, links[2].Internal)
+	assert.Equal(t, LinkSourceArea, links[3].Source)
+	assert.Equal(t, "area link", links[3].Text)
+}
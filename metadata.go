@@ -0,0 +1,238 @@
+package pageviewer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// Metadata 从渲染后 DOM 中提取的页面元数据
+type Metadata struct {
+	Title       string              `json:"title"`
+	Description string              `json:"description"`
+	Canonical   string              `json:"canonical"`  // link[rel=canonical] 的绝对地址
+	Alternates  []HreflangAlternate `json:"alternates"` // link[rel=alternate][hreflang]
+	Robots      string              `json:"robots"`     // meta[name=robots] 的 content
+	OpenGraph   map[string][]string `json:"openGraph"`  // og:* / article:* 等 meta[property]，按出现顺序保留重复值
+	Twitter     map[string][]string `json:"twitter"`    // twitter:* 卡片属性
+	Meta        map[string][]string `json:"meta"`       // 其余 meta[name] / meta[property]，例如 viewport、fb:app_id
+	JSONLD      []json.RawMessage   `json:"jsonLd"`     // 可解析的 application/ld+json 块，无法解析的块会被跳过
+	Microdata   []MicrodataItem     `json:"microdata"`  // 顶层 itemscope 元素
+}
+
+// HreflangAlternate 多语言备用页面
+type HreflangAlternate struct {
+	Hreflang string `json:"hreflang"`
+	URL      string `json:"url"`
+}
+
+// MicrodataItem schema.org microdata 条目
+type MicrodataItem struct {
+	Type       []string                    `json:"type"`
+	ID         string                      `json:"id,omitempty"`
+	Properties map[string][]MicrodataValue `json:"properties"`
+}
+
+// MicrodataValue microdata 属性值，嵌套 itemscope 时 Item 非空，否则为 Text
+type MicrodataValue struct {
+	Text string         `json:"text,omitempty"`
+	Item *MicrodataItem `json:"item,omitempty"`
+}
+
+type rawMetadata struct {
+	Title       string              `json:"title"`
+	Description string              `json:"description"`
+	Canonical   string              `json:"canonical"`
+	Alternates  []HreflangAlternate `json:"alternates"`
+	Robots      string              `json:"robots"`
+	Meta        []rawMetaProperty   `json:"meta"`
+	JSONLD      []string            `json:"jsonLd"`
+	Microdata   []MicrodataItem     `json:"microdata"`
+}
+
+type rawMetaProperty struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// Metadata 返回页面标题、描述、canonical、hreflang、robots、OpenGraph、Twitter 卡片、JSON-LD 和 microdata
+func (c *Client) Metadata(ctx context.Context, url string, opts ...RequestOption) (Metadata, error) {
+	var metadata Metadata
+	err := c.visitWithOptions(ctx, url, NewRequestOptions(opts...), true, func(page *rod.Page, _ *proto.NetworkResponseReceived) error {
+		var err error
+		metadata, err = collectMetadata(page)
+		return err
+	})
+	return metadata, err
+}
+
+func collectMetadata(page *rod.Page) (Metadata, error) {
+	r, err := page.Eval(`() => {
+		const attr = (el, name) => (el && el.getAttribute(name)) || '';
+		const resolve = (value) => {
+			try {
+				return new URL(value, document.baseURI).href;
+			} catch (e) {
+				return value;
+			}
+		};
+		const metaContent = (name) => {
+			for (const el of document.querySelectorAll('meta[name]')) {
+				if (attr(el, 'name').toLowerCase() === name) {
+					return attr(el, 'content');
+				}
+			}
+			return '';
+		};
+
+		const meta = [];
+		document.querySelectorAll('meta[property], meta[name]').forEach((el) => {
+			const key = (attr(el, 'property') || attr(el, 'name')).trim().toLowerCase();
+			if (key) {
+				meta.push({ key, value: attr(el, 'content') });
+			}
+		});
+
+		const canonical = document.querySelector('link[rel~="canonical"][href]');
+		const alternates = Array.from(document.querySelectorAll('link[rel~="alternate"][hreflang][href]')).map((el) => ({
+			hreflang: attr(el, 'hreflang'),
+			url: resolve(attr(el, 'href')),
+		}));
+
+		const microdataValue = (el) => {
+			const tag = el.tagName.toLowerCase();
+			switch (tag) {
+			case 'meta':
+				return attr(el, 'content');
+			case 'audio': case 'embed': case 'iframe': case 'img': case 'source': case 'track': case 'video':
+				return el.hasAttribute('src') ? resolve(attr(el, 'src')) : '';
+			case 'a': case 'area': case 'link':
+				return el.hasAttribute('href') ? resolve(attr(el, 'href')) : '';
+			case 'object':
+				return el.hasAttribute('data') ? resolve(attr(el, 'data')) : '';
+			case 'data': case 'meter':
+				return attr(el, 'value');
+			case 'time':
+				return attr(el, 'datetime') || el.textContent.trim();
+			default:
+				return el.textContent.trim();
+			}
+		};
+		const microdataItem = (scope) => {
+			const properties = {};
+			const walk = (node) => {
+				for (const child of node.children) {
+					const isScope = child.hasAttribute('itemscope');
+					if (child.hasAttribute('itemprop')) {
+						const value = isScope ? { item: microdataItem(child) } : { text: microdataValue(child) };
+						attr(child, 'itemprop').split(/\s+/).filter(Boolean).forEach((name) => {
+							(properties[name] = properties[name] || []).push(value);
+						});
+					}
+					if (!isScope) {
+						walk(child);
+					}
+				}
+			};
+			walk(scope);
+			return {
+				type: attr(scope, 'itemtype').split(/\s+/).filter(Boolean),
+				id: attr(scope, 'itemid'),
+				properties,
+			};
+		};
+
+		return {
+			title: document.title || '',
+			description: metaContent('description'),
+			canonical: canonical ? resolve(attr(canonical, 'href')) : '',
+			alternates,
+			robots: metaContent('robots'),
+			meta,
+			jsonLd: Array.from(document.querySelectorAll('script[type="application/ld+json"]')).map((el) => el.textContent || ''),
+			microdata: Array.from(document.querySelectorAll('[itemscope]:not([itemprop])')).map(microdataItem),
+		};
+	}`)
+	if err != nil {
+		return Metadata{}, err
+	}
+
+	var raw rawMetadata
+	if err := r.Value.Unmarshal(&raw); err != nil {
+		return Metadata{}, err
+	}
+	return newMetadata(raw), nil
+}
+
+func newMetadata(raw rawMetadata) Metadata {
+	metadata := Metadata{
+		Title:       strings.TrimSpace(raw.Title),
+		Description: strings.TrimSpace(raw.Description),
+		Canonical:   raw.Canonical,
+		Alternates:  raw.Alternates,
+		Robots:      strings.TrimSpace(raw.Robots),
+		OpenGraph:   map[string][]string{},
+		Twitter:     map[string][]string{},
+		Meta:        map[string][]string{},
+		JSONLD:      make([]json.RawMessage, 0, len(raw.JSONLD)),
+		Microdata:   raw.Microdata,
+	}
+	if metadata.Alternates == nil {
+		metadata.Alternates = []HreflangAlternate{}
+	}
+	if metadata.Microdata == nil {
+		metadata.Microdata = []MicrodataItem{}
+	}
+
+	for _, property := range raw.Meta {
+		switch {
+		case strings.HasPrefix(property.Key, "twitter:"):
+			metadata.Twitter[property.Key] = append(metadata.Twitter[property.Key], property.Value)
+		case isOpenGraphProperty(property.Key):
+			metadata.OpenGraph[property.Key] = append(metadata.OpenGraph[property.Key], property.Value)
+		default:
+			metadata.Meta[property.Key] = append(metadata.Meta[property.Key], property.Value)
+		}
+	}
+
+	for _, block := range raw.JSONLD {
+		if data, ok := cleanJSONLD(block); ok {
+			metadata.JSONLD = append(metadata.JSONLD, data)
+		}
+	}
+	return metadata
+}
+
+// isOpenGraphProperty 判断是否为 OpenGraph 协议定义的属性，包括 og:* 和各对象类型的命名空间；
+// fb:* 是 Facebook 自己的扩展，不属于 OpenGraph
+func isOpenGraphProperty(key string) bool {
+	prefix, _, ok := strings.Cut(key, ":")
+	if !ok {
+		return false
+	}
+	switch prefix {
+	case "og", "article", "book", "profile", "music", "video":
+		return true
+	default:
+		return false
+	}
+}
+
+// cleanJSONLD 去掉常见的 HTML 注释 / CDATA 包裹后校验 JSON，返回压缩后的内容
+func cleanJSONLD(block string) (json.RawMessage, bool) {
+	block = strings.TrimSpace(block)
+	for _, wrapper := range [][2]string{{"<!--", "-->"}, {"//<![CDATA[", "//]]>"}, {"<![CDATA[", "]]>"}} {
+		if strings.HasPrefix(block, wrapper[0]) && strings.HasSuffix(block, wrapper[1]) {
+			block = strings.TrimSpace(block[len(wrapper[0]) : len(block)-len(wrapper[1])])
+		}
+	}
+	var compacted bytes.Buffer
+	if block == "" || json.Compact(&compacted, []byte(block)) != nil {
+		return nil, false
+	}
+	return json.RawMessage(compacted.Bytes()), true
+}
@@ -0,0 +1,110 @@
+package pageviewer
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewMetadataGroupsMetaProperties(t *testing.T) {
+	metadata := newMetadata(rawMetadata{
+		Title: "  Title ",
+		Meta: []rawMetaProperty{
+			{Key: "og:title", Value: "OG Title"},
+			{Key: "og:image", Value: "https://example.com/1.png"},
+			{Key: "og:image", Value: "https://example.com/2.png"},
+			{Key: "article:published_time", Value: "2024-01-02"},
+			{Key: "twitter:card", Value: "summary"},
+			{Key: "fb:app_id", Value: "123"},
+			{Key: "viewport", Value: "width=device-width"},
+		},
+		JSONLD: []string{
+			"<!-- {\"@type\": \"Article\", \"name\": \"x\"} -->",
+			"{ invalid, }",
+			"",
+		},
+	})
+
+	assert.Equal(t, "Title", metadata.Title)
+	assert.Equal(t, map[string][]string{
+		"og:title":               {"OG Title"},
+		"og:image":               {"https://example.com/1.png", "https://example.com/2.png"},
+		"article:published_time": {"2024-01-02"},
+	}, metadata.OpenGraph)
+	assert.Equal(t, map[string][]string{"twitter:card": {"summary"}}, metadata.Twitter)
+	assert.Equal(t, map[string][]string{"fb:app_id": {"123"}, "viewport": {"width=device-width"}}, metadata.Meta)
+	require.Len(t, metadata.JSONLD, 1)
+	assert.JSONEq(t, `{"@type":"Article","name":"x"}`, string(metadata.JSONLD[0]))
+	assert.Empty(t, metadata.Alternates)
+	assert.NotNil(t, metadata.Alternates)
+	assert.NotNil(t, metadata.Microdata)
+}
+
+func TestCleanJSONLDKeepsKeyOrder(t *testing.T) {
+	data, ok := cleanJSONLD("//<![CDATA[\n{\"b\": 1, \"a\": [1, 2]}\n//]]>")
+	require.True(t, ok)
+	assert.Equal(t, `{"b":1,"a":[1,2]}`, string(data))
+}
+
+func TestClientMetadataExtractsStructuredData(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(`<!doctype html>
+<html>
+<head>
+  <title>Metadata Page</title>
+  <meta name="Description" content="page description">
+  <meta name="robots" content="noindex, nofollow">
+  <link rel="canonical" href="/canonical">
+  <link rel="alternate" hreflang="de" href="/de">
+  <meta property="og:title" content="OG Title">
+  <meta property="og:image" content="https://example.com/a.png">
+  <meta name="twitter:card" content="summary_large_image">
+  <script type="application/ld+json">{"@context": "https://schema.org", "@type": "Article", "headline": "Headline"}</script>
+</head>
+<body>
+  <div itemscope itemtype="https://schema.org/Product" itemid="urn:product:1">
+    <span itemprop="name">Widget</span>
+    <img itemprop="image" src="/widget.png">
+    <div itemprop="offers" itemscope itemtype="https://schema.org/Offer">
+      <meta itemprop="price" content="9.99">
+    </div>
+  </div>
+</body>
+</html>`))
+	}))
+	defer s.Close()
+
+	client := newTestClient(t, Config{PoolSize: 1, Warmup: 1})
+
+	metadata, err := client.Metadata(context.Background(), s.URL)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Metadata Page", metadata.Title)
+	assert.Equal(t, "page description", metadata.Description)
+	assert.Equal(t, "noindex, nofollow", metadata.Robots)
+	assert.Equal(t, s.URL+"/canonical", metadata.Canonical)
+	assert.Equal(t, []HreflangAlternate{{Hreflang: "de", URL: s.URL + "/de"}}, metadata.Alternates)
+	assert.Equal(t, []string{"OG Title"}, metadata.OpenGraph["og:title"])
+	assert.Equal(t, []string{"summary_large_image"}, metadata.Twitter["twitter:card"])
+
+	require.Len(t, metadata.JSONLD, 1)
+	var article map[string]any
+	require.NoError(t, json.Unmarshal(metadata.JSONLD[0], &article))
+	assert.Equal(t, "Headline", article["headline"])
+
+	require.Len(t, metadata.Microdata, 1)
+	product := metadata.Microdata[0]
+	assert.Equal(t, []string{"https://schema.org/Product"}, product.Type)
+	assert.Equal(t, "urn:product:1", product.ID)
+	assert.Equal(t, []MicrodataValue{{Text: "Widget"}}, product.Properties["name"])
+	assert.Equal(t, []MicrodataValue{{Text: s.URL + "/widget.png"}}, product.Properties["image"])
+	require.Len(t, product.Properties["offers"], 1)
+	require.NotNil(t, product.Properties["offers"][0].Item)
+	assert.Equal(t, []MicrodataValue{{Text: "9.99"}}, product.Properties["offers"][0].Item.Properties["price"])
+}
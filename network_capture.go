@@ -0,0 +1,184 @@
+package pageviewer
+
+import (
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// networkEntry 一次网络请求的事件汇总，重定向的每一跳单独记录
+type networkEntry struct {
+	RequestID         proto.NetworkRequestID
+	ResourceType      proto.NetworkResourceType
+	Request           *proto.NetworkRequest
+	Response          *proto.NetworkResponse
+	StartedAt         time.Time
+	StartTimestamp    proto.MonotonicTime
+	EndTimestamp      proto.MonotonicTime
+	EncodedDataLength float64
+	DataLength        int
+	Body              []byte
+	ErrorText         string
+	Canceled          bool
+	RedirectURL       string
+}
+
+// networkCapture 在请求期间订阅 Network.* 事件，停止后才能读取 entries
+type networkCapture struct {
+	entries  []*networkEntry
+	byID     map[proto.NetworkRequestID]*networkEntry
+	withBody bool
+	stop     func()
+
+	// 被 Fetch 拦截后主动中止的主文档不会触发 Network.loadingFinished，由调用方补充响应头和响应体
+	document     *proto.NetworkResponseReceived
+	documentBody []byte
+}
+
+func startNetworkCapture(page *rod.Page, withBody bool) (*networkCapture, error) {
+	// 显式开启 Network 域，避免其他 EachEvent 监听结束时把域关掉导致事件丢失
+	if err := (proto.NetworkEnable{}).Call(page); err != nil {
+		return nil, err
+	}
+
+	capture := &networkCapture{
+		byID:     map[proto.NetworkRequestID]*networkEntry{},
+		withBody: withBody,
+	}
+
+	capturePage, cancel := page.WithCancel()
+	wait := capturePage.EachEvent(
+		func(e *proto.NetworkRequestWillBeSent) {
+			if previous := capture.byID[e.RequestID]; previous != nil && e.RedirectResponse != nil {
+				previous.Response = e.RedirectResponse
+				previous.EndTimestamp = e.Timestamp
+				previous.RedirectURL = e.Request.URL
+			}
+			entry := &networkEntry{
+				RequestID:      e.RequestID,
+				ResourceType:   e.Type,
+				Request:        e.Request,
+				StartedAt:      e.WallTime.Time(),
+				StartTimestamp: e.Timestamp,
+			}
+			capture.entries = append(capture.entries, entry)
+			capture.byID[e.RequestID] = entry
+		},
+		func(e *proto.NetworkResponseReceived) {
+			if entry := capture.byID[e.RequestID]; entry != nil {
+				entry.Response = e.Response
+				if entry.ResourceType == "" {
+					entry.ResourceType = e.Type
+				}
+			}
+		},
+		func(e *proto.NetworkDataReceived) {
+			if entry := capture.byID[e.RequestID]; entry != nil {
+				entry.DataLength += e.DataLength
+			}
+		},
+		func(e *proto.NetworkLoadingFinished) {
+			entry := capture.byID[e.RequestID]
+			if entry == nil {
+				return
+			}
+			entry.EndTimestamp = e.Timestamp
+			entry.EncodedDataLength = e.EncodedDataLength
+			if capture.withBody {
+				if body, err := getResponseBodyBytes(capturePage, e.RequestID); err == nil {
+					entry.Body = body
+				}
+			}
+		},
+		func(e *proto.NetworkLoadingFailed) {
+			if entry := capture.byID[e.RequestID]; entry != nil {
+				entry.EndTimestamp = e.Timestamp
+				entry.ErrorText = e.ErrorText
+				entry.Canceled = e.Canceled
+			}
+		},
+	)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		wait()
+	}()
+
+	capture.stop = func() {
+		cancel()
+		<-done
+	}
+	return capture, nil
+}
+
+// startRequestCapture 按请求选项决定是否订阅网络事件，只有 WARC 需要读取响应体
+func startRequestCapture(page *rod.Page, ro RequestOptions) (*networkCapture, error) {
+	if ro.WARC == nil && ro.NetworkLog == nil {
+		return nil, nil
+	}
+	return startNetworkCapture(page, ro.WARC != nil)
+}
+
+// finishRequestCapture 在请求结束时生成 HAR 并挂到 trace 上，同时写出 WARC 记录
+func finishRequestCapture(ro RequestOptions, capture *networkCapture, trace *traceSession, err error) error {
+	if capture == nil {
+		return nil
+	}
+
+	entries := capture.finish()
+	traceID, attempt := trace.current(err)
+	if ro.NetworkLog != nil {
+		har := newHAR(entries, attempt)
+		*ro.NetworkLog = *har
+		trace.setNetworkLog(har)
+	}
+	if ro.WARC != nil {
+		return ro.WARC.writeCapture(entries, traceID, attempt)
+	}
+	return nil
+}
+
+// setDocument 记录调用方直接读取到的主文档响应，停止订阅后再合并到对应的 entry，避免和事件回调并发修改
+func (nc *networkCapture) setDocument(document *proto.NetworkResponseReceived, body []byte) {
+	if nc == nil || document == nil {
+		return
+	}
+	nc.document = document
+	nc.documentBody = body
+}
+
+func (nc *networkCapture) finish() []*networkEntry {
+	if nc == nil {
+		return nil
+	}
+	if nc.stop != nil {
+		nc.stop()
+		nc.stop = nil
+		nc.mergeDocument()
+	}
+	return nc.entries
+}
+
+func (nc *networkCapture) mergeDocument() {
+	if nc.document == nil {
+		return
+	}
+	entry := nc.byID[nc.document.RequestID]
+	if entry == nil {
+		return
+	}
+	if entry.Response == nil {
+		entry.Response = nc.document.Response
+		// 中止是读取完响应体后主动发出的，不算加载失败
+		entry.ErrorText = ""
+		entry.Canceled = false
+	}
+	if nc.withBody && entry.Body == nil && nc.documentBody != nil {
+		entry.Body = nc.documentBody
+		if entry.DataLength == 0 {
+			entry.DataLength = len(nc.documentBody)
+		}
+	}
+}
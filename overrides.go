@@ -0,0 +1,226 @@
+package pageviewer
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/devices"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/ysmood/gson"
+)
+
+// errResetOverrides 恢复覆盖项失败，worker 页面状态不可信，需要按损坏处理
+var errResetOverrides = errors.New("pageviewer: reset request overrides failed")
+
+// Geolocation 模拟的地理位置，Accuracy 单位为米
+type Geolocation struct {
+	Latitude  float64
+	Longitude float64
+	Accuracy  float64
+}
+
+func (g Geolocation) validate() error {
+	if g.Latitude < -90 || g.Latitude > 90 || g.Longitude < -180 || g.Longitude > 180 || g.Accuracy < 0 {
+		return fmt.Errorf("pageviewer: invalid geolocation %v,%v accuracy %v", g.Latitude, g.Longitude, g.Accuracy)
+	}
+	return nil
+}
+
+// applyOverrides 在导航之前把请求级的请求头、User-Agent、设备、语言区域、时区、地理位置、cookie 等覆盖项应用到 worker 页面上，
+// 返回按相反顺序恢复到 baseline 设备的函数，归还 worker 之前必须调用，避免覆盖项泄漏给下一个借用者；
+// 应用失败时会立即恢复已生效的部分，恢复也失败时返回的错误包含 errResetOverrides。url 为请求地址，用于限定定位权限的来源
+func applyOverrides(page *rod.Page, baseline devices.Device, url string, ro RequestOptions) (func() error, error) {
+	var resets []func() error
+	reset := func() error {
+		var errs []error
+		for i := len(resets) - 1; i >= 0; i-- {
+			errs = append(errs, resets[i]())
+		}
+		return errors.Join(errs...)
+	}
+	fail := func(err error) (func() error, error) {
+		err = fmt.Errorf("pageviewer: apply request overrides: %w", err)
+		if resetErr := reset(); resetErr != nil {
+			err = errors.Join(err, errResetOverrides, resetErr)
+		}
+		return func() error { return nil }, err
+	}
+
+	if ro.Geolocation != nil {
+		if err := ro.Geolocation.validate(); err != nil {
+			return func() error { return nil }, err
+		}
+	}
+	cookies, err := cookieParams(ro.Cookies)
+	if err != nil {
+		return func() error { return nil }, err
+	}
+
+	if len(ro.Headers) > 0 {
+		if err := (proto.NetworkSetExtraHTTPHeaders{Headers: networkHeaders(ro.Headers)}).Call(page); err != nil {
+			return fail(err)
+		}
+		resets = append(resets, func() error {
+			return proto.NetworkSetExtraHTTPHeaders{Headers: proto.NetworkHeaders{}}.Call(page)
+		})
+	}
+
+	if ro.Device != nil {
+		resetDevice, err := applyDevice(page, baseline, *ro.Device)
+		if err != nil {
+			return fail(err)
+		}
+		resets = append(resets, resetDevice)
+	}
+
+	userAgent := ro.UserAgent
+	if userAgent == "" && ro.Device != nil {
+		userAgent = ro.Device.UserAgent
+	}
+	if userAgent != "" || ro.AcceptLanguage != "" {
+		override := proto.EmulationSetUserAgentOverride{UserAgent: userAgent, AcceptLanguage: ro.AcceptLanguage}
+		if override.UserAgent == "" {
+			// 只覆盖 Accept-Language 时沿用当前默认的 User-Agent
+			if ua := baseline.UserAgentEmulation(); ua != nil {
+				override.UserAgent = ua.UserAgent
+			} else {
+				version, err := proto.BrowserGetVersion{}.Call(page)
+				if err != nil {
+					return fail(err)
+				}
+				override.UserAgent = version.UserAgent
+			}
+		}
+		if err := override.Call(page); err != nil {
+			return fail(err)
+		}
+		resets = append(resets, func() error {
+			return resetUserAgent(page, baseline)
+		})
+	}
+
+	if ro.Locale != "" {
+		if err := (proto.EmulationSetLocaleOverride{Locale: ro.Locale}).Call(page); err != nil {
+			return fail(err)
+		}
+		resets = append(resets, func() error {
+			return proto.EmulationSetLocaleOverride{}.Call(page)
+		})
+	}
+
+	if ro.Timezone != "" {
+		if err := (proto.EmulationSetTimezoneOverride{TimezoneID: ro.Timezone}).Call(page); err != nil {
+			return fail(err)
+		}
+		resets = append(resets, func() error {
+			return proto.EmulationSetTimezoneOverride{}.Call(page)
+		})
+	}
+
+	if ro.Geolocation != nil {
+		resetGeolocation, err := applyGeolocation(page, *ro.Geolocation, url)
+		if err != nil {
+			return fail(err)
+		}
+		resets = append(resets, resetGeolocation)
+	}
+
+	if len(cookies) > 0 {
+		resetCookies, err := applyCookies(page, cookies)
+		if err != nil {
+			return fail(err)
+		}
+		resets = append(resets, resetCookies)
+	}
+
+	return reset, nil
+}
+
+// geolocationGrantKey 浏览器上下文中的一个来源，origin 为空表示整个上下文
+type geolocationGrantKey struct {
+	browser   *rod.Browser
+	contextID proto.BrowserBrowserContextID
+	origin    string
+}
+
+// geolocationGrants 记录各来源上仍在使用定位权限的请求数，并发请求共享同一个授权，最后一个请求结束时才改回询问状态
+var geolocationGrants = struct {
+	sync.Mutex
+	m map[geolocationGrantKey]int
+}{m: make(map[geolocationGrantKey]int)}
+
+// applyGeolocation 为请求地址所在的来源授予定位权限并覆盖地理位置，返回恢复函数；
+// 地址没有来源时（例如 file:// 或 data:）授权整个浏览器上下文。跳转到其他来源的页面不会获得授权
+func applyGeolocation(page *rod.Page, geo Geolocation, url string) (func() error, error) {
+	info, err := page.Info()
+	if err != nil {
+		return nil, err
+	}
+	key := geolocationGrantKey{browser: page.Browser(), contextID: info.BrowserContextID}
+	if origins := requestOrigins(url); len(origins) > 0 {
+		key.origin = origins[0]
+	}
+	permission := func(setting proto.BrowserPermissionSetting) error {
+		return proto.BrowserSetPermission{
+			Permission:       &proto.BrowserPermissionDescriptor{Name: "geolocation"},
+			Setting:          setting,
+			Origin:           key.origin,
+			BrowserContextID: info.BrowserContextID,
+		}.Call(page.Browser())
+	}
+
+	geolocationGrants.Lock()
+	if geolocationGrants.m[key] == 0 {
+		if err := permission(proto.BrowserPermissionSettingGranted); err != nil {
+			geolocationGrants.Unlock()
+			return nil, err
+		}
+	}
+	geolocationGrants.m[key]++
+	geolocationGrants.Unlock()
+	revoke := func() error {
+		geolocationGrants.Lock()
+		defer geolocationGrants.Unlock()
+		if geolocationGrants.m[key]--; geolocationGrants.m[key] > 0 {
+			return nil
+		}
+		delete(geolocationGrants.m, key)
+		return permission(proto.BrowserPermissionSettingPrompt)
+	}
+
+	override := proto.EmulationSetGeolocationOverride{Latitude: &geo.Latitude, Longitude: &geo.Longitude, Accuracy: &geo.Accuracy}
+	if err := override.Call(page); err != nil {
+		return nil, errors.Join(err, revoke())
+	}
+
+	return func() error {
+		return errors.Join(
+			proto.EmulationClearGeolocationOverride{}.Call(page),
+			revoke(),
+		)
+	}, nil
+}
+
+// resetUserAgent 恢复 baseline 设备的 User-Agent；baseline 不模拟设备时 UserAgent 为空，浏览器会清除覆盖
+func resetUserAgent(page *rod.Page, baseline devices.Device) error {
+	override := proto.EmulationSetUserAgentOverride{}
+	if ua := baseline.UserAgentEmulation(); ua != nil {
+		override.UserAgent = ua.UserAgent
+		override.AcceptLanguage = ua.AcceptLanguage
+		override.Platform = ua.Platform
+	}
+	return override.Call(page)
+}
+
+// networkHeaders 把 http.Header 转成 CDP 请求头，同名的多个值用逗号合并
+func networkHeaders(header http.Header) proto.NetworkHeaders {
+	headers := make(proto.NetworkHeaders, len(header))
+	for name, values := range header {
+		headers[name] = gson.New(strings.Join(values, ", "))
+	}
+	return headers
+}
@@ -0,0 +1,165 @@
+package pageviewer
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-rod/rod"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNetworkHeadersJoinsValues(t *testing.T) {
+	headers := networkHeaders(http.Header{"X-Token": {"a", "b"}, "X-Single": {"c"}})
+	assert.Equal(t, "a, b", headers["X-Token"].Str())
+	assert.Equal(t, "c", headers["X-Single"].Str())
+}
+
+func TestGeolocationValidate(t *testing.T) {
+	assert.NoError(t, Geolocation{Latitude: 31.23, Longitude: 121.47, Accuracy: 10}.validate())
+	assert.Error(t, Geolocation{Latitude: 91}.validate())
+	assert.Error(t, Geolocation{Longitude: -181}.validate())
+	assert.Error(t, Geolocation{Accuracy: -1}.validate())
+}
+
+func TestClientLocaleTimezoneAndGeolocation(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(`<html><head><title>region</title></head><body>region</body></html>`))
+	}))
+	defer s.Close()
+
+	client := newTestClient(t, Config{PoolSize: 1, Warmup: 1})
+
+	probe := func(opts ...RequestOption) map[string]any {
+		var result map[string]any
+		err := client.Visit(context.Background(), s.URL, func(page *rod.Page) error {
+			r, err := page.Eval(`async () => {
+				const position = await new Promise((resolve) => navigator.geolocation.getCurrentPosition(
+					(p) => resolve({ lat: p.coords.latitude, lon: p.coords.longitude }),
+					(e) => resolve({ error: e.code }),
+					{ timeout: 1000 },
+				));
+				return JSON.stringify({
+					locale: Intl.DateTimeFormat().resolvedOptions().locale,
+					timezone: Intl.DateTimeFormat().resolvedOptions().timeZone,
+					position,
+				});
+			}`)
+			if err != nil {
+				return err
+			}
+			return json.Unmarshal([]byte(r.Value.Str()), &result)
+		}, opts...)
+		require.NoError(t, err)
+		return result
+	}
+
+	result := probe(WithLocale("de-DE"), WithTimezone("Asia/Tokyo"), WithGeolocation(35.68, 139.69, 10))
+	assert.Equal(t, "de-DE", result["locale"])
+	assert.Equal(t, "Asia/Tokyo", result["timezone"])
+	assert.Equal(t, map[string]any{"lat": 35.68, "lon": 139.69}, result["position"])
+
+	result = probe()
+	assert.NotEqual(t, "de-DE", result["locale"])
+	assert.NotEqual(t, "Asia/Tokyo", result["timezone"])
+	assert.Contains(t, result["position"], "error")
+
+	// 非法的时区只让本次请求失败，worker 恢复后仍可复用
+	err := client.Visit(context.Background(), s.URL, func(page *rod.Page) error { return nil }, WithTimezone("Mars/Olympus"))
+	assert.Error(t, err)
+	assert.Equal(t, 1, client.Stats().TotalWorkers)
+	_, err = client.HTML(context.Background(), s.URL)
+	assert.NoError(t, err)
+}
+
+func TestClientRequestOverridesDoNotLeak(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(`<html><head><title>headers</title></head><body>` +
+			`<p id="token">` + r.Header.Get("X-Token") + `</p>` +
+			`<p id="ua">` + r.UserAgent() + `</p>` +
+			`<p id="lang">` + r.Header.Get("Accept-Language") + `</p>` +
+			`</body></html>`))
+	}))
+	defer s.Close()
+
+	client := newTestClient(t, Config{PoolSize: 1, Warmup: 1})
+
+	html, err := client.HTML(context.Background(), s.URL,
+		WithHeaders(http.Header{"X-Token": {"secret"}}),
+		WithUserAgent("pageviewer-test/1.0"),
+		WithAcceptLanguage("fr-FR,fr;q=0.9"),
+	)
+	require.NoError(t, err)
+	assert.Contains(t, html, `<p id="token">secret</p>`)
+	assert.Contains(t, html, `<p id="ua">pageviewer-test/1.0</p>`)
+	assert.Contains(t, html, `<p id="lang">fr-FR,fr;q=0.9</p>`)
+
+	resp, err := client.RawText(context.Background(), s.URL, WithHeaders(http.Header{"X-Token": {"raw"}}))
+	require.NoError(t, err)
+	assert.Contains(t, resp.Body, `<p id="token">raw</p>`)
+
+	html, err = client.HTML(context.Background(), s.URL)
+	require.NoError(t, err)
+	assert.Contains(t, html, `<p id="token"></p>`)
+	assert.NotContains(t, html, "pageviewer-test/1.0")
+	assert.NotContains(t, html, "fr-FR")
+}
+
+func TestClientGeolocationGrantSurvivesConcurrentRequest(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(`<html><body>geo</body></html>`))
+	}))
+	defer s.Close()
+
+	client := newTestClient(t, Config{PoolSize: 2, Warmup: 2})
+	position := func(page *rod.Page) (string, error) {
+		r, err := page.Eval(`() => new Promise((resolve) => navigator.geolocation.getCurrentPosition(
+			(p) => resolve(String(p.coords.latitude)),
+			(e) => resolve('error ' + e.code),
+			{ timeout: 1000 },
+		))`)
+		if err != nil {
+			return "", err
+		}
+		return r.Value.Str(), nil
+	}
+
+	started := make(chan struct{})
+	proceed := make(chan struct{})
+	done := make(chan error, 1)
+	var first string
+	go func() {
+		done <- client.Visit(context.Background(), s.URL, func(page *rod.Page) error {
+			close(started)
+			<-proceed
+			var err error
+			first, err = position(page)
+			return err
+		}, WithGeolocation(10, 20, 1))
+	}()
+
+	<-started
+	// 另一个请求结束时不能撤销仍在使用的定位权限
+	var second string
+	err := client.Visit(context.Background(), s.URL, func(page *rod.Page) error {
+		var err error
+		second, err = position(page)
+		return err
+	}, WithGeolocation(30, 40, 1))
+	require.NoError(t, err)
+	assert.Equal(t, "30", second)
+
+	close(proceed)
+	require.NoError(t, <-done)
+	assert.Equal(t, "10", first)
+
+	geolocationGrants.Lock()
+	assert.Empty(t, geolocationGrants.m)
+	geolocationGrants.Unlock()
+}
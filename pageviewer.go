@@ -2,6 +2,7 @@ package pageviewer
 
 import (
 	"context"
+	"net/http"
 	"time"
 
 	"github.com/go-rod/rod"
@@ -37,6 +38,22 @@ type VisitOptions struct {
 	browser        *Browser // 浏览器对象，只在Visit调用时有效
 	acquireTimeout time.Duration
 	traceID        string
+	warc           *WARCWriter
+	networkLog     *HAR
+	maxBodySize    int64
+	contentTypes   []string
+	undecodedBody  bool
+	dialogPolicy   DialogPolicy
+	followPopup    bool
+	headers        http.Header
+	userAgent      string
+	acceptLanguage string
+	device         *DeviceProfile
+	locale         string
+	timezone       string
+	geolocation    *Geolocation
+	cookies        []*http.Cookie
+	isolated       bool
 }
 
 // VisitOption 访问配置项
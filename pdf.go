@@ -0,0 +1,130 @@
+package pageviewer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+type PDFPaperSize string
+
+const (
+	PDFPaperLetter  PDFPaperSize = "letter"
+	PDFPaperLegal   PDFPaperSize = "legal"
+	PDFPaperTabloid PDFPaperSize = "tabloid"
+	PDFPaperA3      PDFPaperSize = "a3"
+	PDFPaperA4      PDFPaperSize = "a4"
+	PDFPaperA5      PDFPaperSize = "a5"
+)
+
+// pdfPaperSizes 纸张宽高，单位为英寸
+var pdfPaperSizes = map[PDFPaperSize][2]float64{
+	PDFPaperLetter:  {8.5, 11},
+	PDFPaperLegal:   {8.5, 14},
+	PDFPaperTabloid: {11, 17},
+	PDFPaperA3:      {11.69, 16.54},
+	PDFPaperA4:      {8.27, 11.69},
+	PDFPaperA5:      {5.83, 8.27},
+}
+
+// PDFMargins 页边距，单位为英寸
+type PDFMargins struct {
+	Top    float64
+	Bottom float64
+	Left   float64
+	Right  float64
+}
+
+// PDFOptions 打印 PDF 配置项
+type PDFOptions struct {
+	PaperSize         PDFPaperSize // 纸张规格，默认 letter；设置 PaperWidth / PaperHeight 后忽略
+	PaperWidth        float64      // 纸张宽度，单位为英寸
+	PaperHeight       float64      // 纸张高度，单位为英寸
+	Margins           *PDFMargins  // 页边距，nil 时使用浏览器默认边距
+	Landscape         bool         // 是否横向打印
+	PrintBackground   bool         // 是否打印背景图和背景色
+	Scale             float64      // 缩放比例，默认 1
+	PageRanges        string       // 打印页码范围，例如 "1-5, 8"
+	HeaderTemplate    string       // 页眉 HTML 模板，只设置页脚时页眉留空
+	FooterTemplate    string       // 页脚 HTML 模板，只设置页眉时页脚留空
+	PreferCSSPageSize bool         // 优先使用页面 CSS @page 定义的纸张大小
+}
+
+// pdfEmptyTemplate 只设置了页眉或页脚之一时填充另一侧，避免 Chrome 打印默认的日期、标题页眉或地址、页码页脚
+const pdfEmptyTemplate = "<span></span>"
+
+func (po PDFOptions) toProto() (*proto.PagePrintToPDF, error) {
+	req := &proto.PagePrintToPDF{
+		Landscape:         po.Landscape,
+		PrintBackground:   po.PrintBackground,
+		PageRanges:        po.PageRanges,
+		PreferCSSPageSize: po.PreferCSSPageSize,
+	}
+	if po.HeaderTemplate != "" || po.FooterTemplate != "" {
+		req.DisplayHeaderFooter = true
+		req.HeaderTemplate, req.FooterTemplate = po.HeaderTemplate, po.FooterTemplate
+		if req.HeaderTemplate == "" {
+			req.HeaderTemplate = pdfEmptyTemplate
+		}
+		if req.FooterTemplate == "" {
+			req.FooterTemplate = pdfEmptyTemplate
+		}
+	}
+
+	width, height := po.PaperWidth, po.PaperHeight
+	if width <= 0 || height <= 0 {
+		paperSize := PDFPaperSize(strings.ToLower(string(po.PaperSize)))
+		if paperSize == "" {
+			paperSize = PDFPaperLetter
+		}
+		size, ok := pdfPaperSizes[paperSize]
+		if !ok {
+			return nil, fmt.Errorf("pageviewer: unsupported pdf paper size: %s", po.PaperSize)
+		}
+		width, height = size[0], size[1]
+	}
+	req.PaperWidth = &width
+	req.PaperHeight = &height
+
+	if po.Scale != 0 {
+		if po.Scale < 0.1 || po.Scale > 2 {
+			return nil, fmt.Errorf("pageviewer: invalid pdf scale: %v", po.Scale)
+		}
+		scale := po.Scale
+		req.Scale = &scale
+	}
+	if po.Margins != nil {
+		margins := *po.Margins
+		req.MarginTop = &margins.Top
+		req.MarginBottom = &margins.Bottom
+		req.MarginLeft = &margins.Left
+		req.MarginRight = &margins.Right
+	}
+
+	return req, nil
+}
+
+// PDF 把渲染后的页面打印为 PDF
+func (c *Client) PDF(ctx context.Context, url string, po PDFOptions, opts ...RequestOption) ([]byte, error) {
+	req, err := po.toProto()
+	if err != nil {
+		return nil, err
+	}
+
+	var data []byte
+	err = c.visitWithOptions(ctx, url, NewRequestOptions(opts...), true, func(page *rod.Page, _ *proto.NetworkResponseReceived) error {
+		stream, err := page.PDF(req)
+		if err != nil {
+			return err
+		}
+		defer stream.Close()
+
+		data, err = io.ReadAll(stream)
+		return err
+	})
+	return data, err
+}
@@ -0,0 +1,77 @@
+package pageviewer
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPDFOptionsToProto(t *testing.T) {
+	req, err := PDFOptions{}.toProto()
+	require.NoError(t, err)
+	require.NotNil(t, req.PaperWidth)
+	require.NotNil(t, req.PaperHeight)
+	assert.Equal(t, 8.5, *req.PaperWidth)
+	assert.Equal(t, 11.0, *req.PaperHeight)
+	assert.Nil(t, req.MarginTop)
+	assert.Nil(t, req.Scale)
+	assert.False(t, req.DisplayHeaderFooter)
+
+	req, err = PDFOptions{
+		PaperSize:       "A4",
+		Margins:         &PDFMargins{Top: 0.5, Bottom: 0.5},
+		Landscape:       true,
+		PrintBackground: true,
+		Scale:           0.8,
+		PageRanges:      "1-2",
+		FooterTemplate:  `<span class="pageNumber"></span>`,
+	}.toProto()
+	require.NoError(t, err)
+	assert.Equal(t, 8.27, *req.PaperWidth)
+	assert.Equal(t, 0.5, *req.MarginTop)
+	assert.Equal(t, 0.0, *req.MarginLeft)
+	assert.Equal(t, 0.8, *req.Scale)
+	assert.True(t, req.Landscape)
+	assert.True(t, req.PrintBackground)
+	assert.True(t, req.DisplayHeaderFooter)
+	assert.Equal(t, "<span></span>", req.HeaderTemplate)
+	assert.Equal(t, `<span class="pageNumber"></span>`, req.FooterTemplate)
+	assert.Equal(t, "1-2", req.PageRanges)
+
+	req, err = PDFOptions{HeaderTemplate: `<span class="title"></span>`}.toProto()
+	require.NoError(t, err)
+	assert.True(t, req.DisplayHeaderFooter)
+	assert.Equal(t, `<span class="title"></span>`, req.HeaderTemplate)
+	assert.Equal(t, "<span></span>", req.FooterTemplate)
+
+	req, err = PDFOptions{PaperSize: "a4", PaperWidth: 4, PaperHeight: 6}.toProto()
+	require.NoError(t, err)
+	assert.Equal(t, 4.0, *req.PaperWidth)
+	assert.Equal(t, 6.0, *req.PaperHeight)
+}
+
+func TestPDFOptionsRejectsInvalidValues(t *testing.T) {
+	_, err := PDFOptions{PaperSize: "b5"}.toProto()
+	assert.ErrorContains(t, err, "unsupported pdf paper size")
+
+	_, err = PDFOptions{Scale: 3}.toProto()
+	assert.ErrorContains(t, err, "invalid pdf scale")
+}
+
+func TestClientPDFPrintsPage(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><body><h1>printable</h1></body></html>`))
+	}))
+	defer s.Close()
+
+	client := newTestClient(t, Config{PoolSize: 1, Warmup: 1})
+
+	data, err := client.PDF(context.Background(), s.URL, PDFOptions{PaperSize: PDFPaperA4, PrintBackground: true})
+	require.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(data, []byte("%PDF-")))
+}
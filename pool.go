@@ -12,9 +12,13 @@ import (
 var errWorkerPoolFull = errors.New("pageviewer: worker pool full")
 
 type worker struct {
-	id      int
-	page    *rod.Page
-	closeFn func() error
+	id        int
+	page      *rod.Page
+	closeFn   func() error
+	dialogs   *dialogHandler
+	popups    *popupTracker
+	createdAt time.Time
+	requests  int // 已处理的请求数，只由持有 worker 的请求读写
 }
 
 type workerState int
@@ -22,6 +26,7 @@ type workerState int
 const (
 	workerStateReady workerState = iota
 	workerStateBroken
+	workerStateRecycle // 达到回收条件，不再归还池，由后台替换为新的 worker
 )
 
 type workerPool struct {
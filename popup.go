@@ -0,0 +1,165 @@
+package pageviewer
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// popupTracker 在 worker 整个生命周期内跟踪由 worker 页面通过 window.open 或 target=_blank 打开的新页面，
+// 请求结束归还 worker 时统一关闭，避免遗留的页面在共享 Browser 上累积
+type popupTracker struct {
+	browser  *rod.Browser
+	openerID proto.TargetTargetID
+	mu       sync.Mutex
+	targets  []proto.TargetTargetID
+	opened   int
+	stop     func()
+}
+
+func newPopupTracker(browser *rod.Browser, page *rod.Page) *popupTracker {
+	t := &popupTracker{browser: browser, openerID: page.TargetID}
+	listenBrowser, cancel := browser.WithCancel()
+	t.stop = cancel
+
+	wait := listenBrowser.EachEvent(
+		func(e *proto.TargetTargetCreated) {
+			if e.TargetInfo == nil || e.TargetInfo.Type != proto.TargetTargetInfoTypePage || e.TargetInfo.OpenerID != t.openerID {
+				return
+			}
+			t.mu.Lock()
+			t.targets = append(t.targets, e.TargetInfo.TargetID)
+			t.opened++
+			t.mu.Unlock()
+		},
+		func(e *proto.TargetTargetDestroyed) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			for i, id := range t.targets {
+				if id == e.TargetID {
+					t.targets = append(t.targets[:i], t.targets[i+1:]...)
+					return
+				}
+			}
+		},
+	)
+	go wait()
+	return t
+}
+
+// latest 返回最近打开且仍然存在的新页面，没有时返回 nil
+func (t *popupTracker) latest() (*rod.Page, error) {
+	if t == nil {
+		return nil, nil
+	}
+
+	t.mu.Lock()
+	if len(t.targets) == 0 {
+		t.mu.Unlock()
+		return nil, nil
+	}
+	id := t.targets[len(t.targets)-1]
+	t.mu.Unlock()
+
+	return t.browser.PageFromTarget(id)
+}
+
+// release 关闭全部跟踪中的新页面，返回上次 release 之后新打开的页面数和本次关闭的页面数
+func (t *popupTracker) release() (int, int) {
+	if t == nil {
+		return 0, 0
+	}
+
+	t.mu.Lock()
+	targets := t.targets
+	opened := t.opened
+	t.targets, t.opened = nil, 0
+	t.mu.Unlock()
+
+	closed := 0
+	for _, id := range targets {
+		if _, err := (proto.TargetCloseTarget{TargetID: id}).Call(t.browser); err == nil {
+			closed++
+		}
+	}
+	return opened, closed
+}
+
+func (t *popupTracker) close() {
+	if t == nil {
+		return
+	}
+	t.release()
+	if t.stop != nil {
+		t.stop()
+	}
+}
+
+// followPopup 等待新页面完成跳转和加载，再按 WaitStrategy 做稳定等待，返回新页面和它的主文档响应
+func (b *Browser) followPopup(ctx context.Context, popup *rod.Page, po *PageOptions) (*rod.Page, *proto.NetworkResponseReceived, error) {
+	popup = popup.Context(ctx)
+	timeout := po.waitTimeout
+	if timeout <= 0 {
+		timeout = DefaultWaitStableTimeout
+	}
+
+	s := time.Now()
+	err := popup.Timeout(timeout).Wait(rod.Eval(`() => location.href !== 'about:blank' && document.readyState === 'complete'`))
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, nil, ctx.Err()
+		}
+		if !errors.Is(err, context.DeadlineExceeded) {
+			return nil, nil, err
+		}
+	}
+
+	followOptions := *po
+	followOptions.waitTimeout = timeout - time.Since(s)
+	if err := b.WaitPage(popup, &followOptions); err != nil {
+		return nil, nil, err
+	}
+	response, err := popupDocumentResponse(popup)
+	if err != nil {
+		return nil, nil, err
+	}
+	return popup, response, nil
+}
+
+// popupDocumentResponse 用 Navigation Timing 和 document.contentType 还原新页面的主文档响应；
+// 新页面在跟随之前已经开始加载，拿不到它的 Network 事件，因此 RequestID 为空，响应体由 readResponseBody 从资源树读取
+func popupDocumentResponse(popup *rod.Page) (*proto.NetworkResponseReceived, error) {
+	r, err := popup.Eval(`() => {
+		const nav = performance.getEntriesByType('navigation')[0];
+		return {
+			url: location.href,
+			status: (nav && nav.responseStatus) || 0,
+			contentType: document.contentType || '',
+		};
+	}`)
+	if err != nil {
+		return nil, err
+	}
+
+	var document struct {
+		URL         string `json:"url"`
+		Status      int    `json:"status"`
+		ContentType string `json:"contentType"`
+	}
+	if err := r.Value.Unmarshal(&document); err != nil {
+		return nil, err
+	}
+	return &proto.NetworkResponseReceived{
+		Type:    proto.NetworkResourceTypeDocument,
+		FrameID: popup.FrameID,
+		Response: &proto.NetworkResponse{
+			URL:      document.URL,
+			Status:   document.Status,
+			MIMEType: mediaType(document.ContentType),
+		},
+	}, nil
+}
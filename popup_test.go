@@ -0,0 +1,112 @@
+package pageviewer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPopupTrackerNilSafe(t *testing.T) {
+	var tracker *popupTracker
+	page, err := tracker.latest()
+	assert.NoError(t, err)
+	assert.Nil(t, page)
+
+	opened, closed := tracker.release()
+	assert.Zero(t, opened)
+	assert.Zero(t, closed)
+	tracker.close()
+}
+
+func TestClientTracksAndClosesPopups(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(`<html><head><title>opener</title></head><body><p>opener page</p>
+<button id="open" onclick="window.open('/popup')">open</button>
+<a id="blank" href="/popup" target="_blank">blank</a>
+</body></html>`))
+	})
+	mux.HandleFunc("/popup", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(`<html><head><title>popup</title></head><body><p>popup page</p></body></html>`))
+	})
+	s := httptest.NewServer(mux)
+	defer s.Close()
+
+	client := newTestClient(t, Config{PoolSize: 1, Warmup: 1})
+
+	html, err := client.HTML(context.Background(), s.URL, WithTraceID("popup-follow"), WithActions(ActionClick("#open"), ActionWait(WaitDelay(300*time.Millisecond))), WithFollowPopup(true))
+	require.NoError(t, err)
+	assert.Contains(t, html, "popup page")
+
+	trace, ok := client.DebugTrace("popup-follow")
+	require.True(t, ok)
+	assert.Equal(t, 1, trace.Popups)
+	assert.Equal(t, s.URL+"/popup", trace.FinalURL)
+	assert.Equal(t, http.StatusOK, trace.StatusCode)
+	assert.Equal(t, "text/html", trace.ContentType)
+
+	article, err := client.ReadabilityArticle(context.Background(), s.URL, WithActions(ActionClick("#open"), ActionWait(WaitDelay(300*time.Millisecond))), WithFollowPopup(true))
+	require.NoError(t, err)
+	assert.Contains(t, article.RawHTML, "<title>popup</title>")
+
+	html, err = client.HTML(context.Background(), s.URL, WithActions(ActionClick("#blank"), ActionWait(WaitDelay(300*time.Millisecond))))
+	require.NoError(t, err)
+	assert.Contains(t, html, "opener page")
+
+	stats := client.Stats()
+	assert.Equal(t, int64(3), stats.PopupsOpened)
+	assert.Equal(t, int64(3), stats.PopupsClosed)
+
+	pages, err := client.browser.Pages()
+	require.NoError(t, err)
+	for _, page := range pages {
+		info, err := page.Info()
+		require.NoError(t, err)
+		assert.NotContains(t, info.URL, "/popup")
+	}
+}
+
+func TestClientFollowPopupExcludesPopupTrafficFromNetworkLog(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(`<html><head><title>opener</title></head><body><button id="open" onclick="window.open('/popup')">open</button></body></html>`))
+	})
+	mux.HandleFunc("/popup", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(`<html><head><title>popup</title><link rel="stylesheet" href="/popup.css"></head><body><p>popup page</p></body></html>`))
+	})
+	mux.HandleFunc("/popup.css", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/css")
+		_, _ = w.Write([]byte(`p { color: red; }`))
+	})
+	s := httptest.NewServer(mux)
+	defer s.Close()
+
+	client := newTestClient(t, Config{PoolSize: 1, Warmup: 1})
+
+	var har HAR
+	html, err := client.HTML(context.Background(), s.URL, WithTraceID("popup-har"), WithNetworkLog(&har), WithActions(ActionClick("#open"), ActionWait(WaitDelay(300*time.Millisecond))), WithFollowPopup(true))
+	require.NoError(t, err)
+	assert.Contains(t, html, "popup page")
+
+	trace, ok := client.DebugTrace("popup-har")
+	require.True(t, ok)
+	assert.Equal(t, s.URL+"/popup", trace.FinalURL)
+
+	// 只记录 worker 页面的请求，新页面的主文档和子资源都不在 HAR 中
+	urls := make([]string, 0, len(har.Log.Entries))
+	for _, entry := range har.Log.Entries {
+		urls = append(urls, entry.Request.URL)
+	}
+	assert.Contains(t, urls, s.URL+"/")
+	assert.NotContains(t, urls, s.URL+"/popup")
+	assert.NotContains(t, urls, s.URL+"/popup.css")
+}
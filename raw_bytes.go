@@ -0,0 +1,325 @@
+package pageviewer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/ysmood/gson"
+)
+
+// DefaultMaxBodySize RawBytes 默认允许的最大响应体字节数
+const DefaultMaxBodySize int64 = 64 << 20
+
+// BytesResponse 主文档的原始响应，Body 为解码后的字节，适用于 PDF、图片、压缩包等任意类型
+type BytesResponse struct {
+	Body        []byte
+	ContentType string
+	StatusCode  int
+	FinalURL    string
+	Header      http.Header
+}
+
+// RawBytes 返回主文档解码后的原始字节，不做内容类型检查也不渲染页面。
+// 超过 WithMaxBodySize 限制时返回 ErrBodyTooLarge，不在 WithAllowedContentTypes 列表中时返回 ErrUnsupportedContentType
+func (c *Client) RawBytes(ctx context.Context, url string, opts ...RequestOption) (resp BytesResponse, err error) {
+	ro := NewRequestOptions(opts...)
+	err = c.runOnWorker(ctx, url, ro, traceModeBytes, func(req *workerRequest) (*proto.NetworkResponseReceived, error) {
+		result, err := c.browser.navigateRawPage(ctx, req.page, url, c.pageOptions(ro), rawBodyLimits{
+			maxSize:      ro.MaxBodySize,
+			contentTypes: ro.AllowedContentTypes,
+		})
+		req.capture.setDocument(result.response, result.data)
+		if err != nil {
+			// 超限和类型不符只是拒绝了本次响应，页面本身仍可复用
+			if !errors.Is(err, ErrBodyTooLarge) && !errors.Is(err, ErrUnsupportedContentType) {
+				req.state = workerStateBroken
+			}
+			return result.response, err
+		}
+		resp = newBytesResponse(result.data, result.response)
+		return result.response, nil
+	})
+	if err != nil {
+		return BytesResponse{}, err
+	}
+	return resp, nil
+}
+
+func newBytesResponse(body []byte, document *proto.NetworkResponseReceived) BytesResponse {
+	if document == nil || document.Response == nil {
+		return BytesResponse{Body: body}
+	}
+
+	return BytesResponse{
+		Body:        body,
+		ContentType: document.Response.MIMEType,
+		StatusCode:  document.Response.Status,
+		FinalURL:    document.Response.URL,
+		Header:      newHTTPHeader(document.Response.Headers),
+	}
+}
+
+type rawBodyLimits struct {
+	maxSize      int64    // <= 0 时使用 DefaultMaxBodySize
+	contentTypes []string // 为空时不限制
+}
+
+func (l rawBodyLimits) limit() int64 {
+	if l.maxSize <= 0 {
+		return DefaultMaxBodySize
+	}
+	return l.maxSize
+}
+
+// documentBodyTimeout 等待主文档在响应阶段暂停的上限，使用 po.waitTimeout，未设置时为 DefaultWaitStableTimeout
+func documentBodyTimeout(po *PageOptions) time.Duration {
+	if po != nil && po.waitTimeout > 0 {
+		return po.waitTimeout
+	}
+	return DefaultWaitStableTimeout
+}
+
+// navigateRawPage 在响应阶段拦截主文档，以流的方式读取原始字节后中止导航，
+// 避免 PDF、压缩包等响应被浏览器交给下载或内置查看器处理；
+// file://、data: 或 Service Worker 返回的主文档不会在响应阶段暂停，加载完成后改用 Network.getResponseBody 读取，
+// 两者都没有在 po.waitTimeout 内出现时返回 ErrNavigationFailed
+func (b *Browser) navigateRawPage(ctx context.Context, page *rod.Page, u string, po *PageOptions, limits rawBodyLimits) (documentResponseResult, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if po.beforeRequest != nil {
+		if err := po.beforeRequest(page); err != nil {
+			return documentResponseResult{}, err
+		}
+	}
+
+	if err := (proto.FetchEnable{Patterns: []*proto.FetchRequestPattern{{
+		URLPattern:   "*",
+		ResourceType: proto.NetworkResourceTypeDocument,
+		RequestStage: proto.FetchRequestStageResponse,
+	}}}).Call(page); err != nil {
+		return documentResponseResult{}, err
+	}
+	defer func() {
+		_ = proto.FetchDisable{}.Call(page)
+	}()
+
+	waitPage, cancel := page.WithCancel()
+	resultCh := make(chan documentResponseResult, 1)
+	paused := make(chan struct{})
+	done := make(chan struct{})
+	var document *proto.NetworkResponseReceived
+	wait := waitPage.EachEvent(func(e *proto.FetchRequestPaused) bool {
+		if e.ResponseErrorReason != "" {
+			resultCh <- documentResponseResult{err: fmt.Errorf("%w: %s", ErrNavigationFailed, e.ResponseErrorReason)}
+			_ = proto.FetchContinueRequest{RequestID: e.RequestID}.Call(waitPage)
+			return true
+		}
+		if e.ResponseStatusCode == nil || isRedirectResponse(*e.ResponseStatusCode, e.ResponseHeaders) {
+			_ = proto.FetchContinueRequest{RequestID: e.RequestID}.Call(waitPage)
+			return false
+		}
+
+		// 结果必须先于中止请求写入，Navigate 会在请求被中止后立即返回
+		close(paused)
+		resultCh <- readPausedDocument(waitPage, e, limits)
+		_ = proto.FetchFailRequest{RequestID: e.RequestID, ErrorReason: proto.NetworkErrorReasonAborted}.Call(waitPage)
+		return true
+	}, func(e *proto.NetworkResponseReceived) {
+		if e.Type == proto.NetworkResourceTypeDocument && document == nil {
+			document = e
+		}
+	}, func(e *proto.NetworkLoadingFinished) bool {
+		// 只有没有经过响应阶段拦截的主文档才会加载完成
+		if document == nil || e.RequestID != document.RequestID {
+			return false
+		}
+		resultCh <- readLoadedDocument(waitPage, document, limits)
+		return true
+	})
+	go func() {
+		defer close(done)
+		wait()
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	navErr := page.Navigate(u)
+	if navErr != nil {
+		select {
+		case result := <-resultCh:
+			return result, result.err
+		default:
+			return documentResponseResult{}, navErr
+		}
+	}
+
+	// 只限制等待主文档出现的时长，已经开始读取的响应体不受影响
+	timer := time.NewTimer(documentBodyTimeout(po))
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return documentResponseResult{}, ctx.Err()
+		case <-done:
+			select {
+			case result := <-resultCh:
+				return result, result.err
+			default:
+				return documentResponseResult{}, ErrNavigationFailed
+			}
+		case result := <-resultCh:
+			return result, result.err
+		case <-paused:
+			paused = nil
+			timer.Stop()
+		case <-timer.C:
+			return documentResponseResult{}, fmt.Errorf("%w: no document response for %s", ErrNavigationFailed, u)
+		}
+	}
+}
+
+// readLoadedDocument 通过 Network.getResponseBody 读取已经加载完成的主文档，按同样的大小和类型限制检查
+func readLoadedDocument(page *rod.Page, document *proto.NetworkResponseReceived, limits rawBodyLimits) documentResponseResult {
+	result := documentResponseResult{response: document}
+	data, err := getResponseBodyBytes(page, document.RequestID)
+	if err != nil {
+		result.err = err
+		return result
+	}
+	if limit := limits.limit(); int64(len(data)) > limit {
+		result.err = newBodyTooLargeError(document.Response.URL, limit)
+		return result
+	}
+	result.data = data
+	if !isAllowedContentType(document.Response.MIMEType, limits.contentTypes) {
+		result.err = fmt.Errorf("%w: %s", ErrUnsupportedContentType, document.Response.MIMEType)
+	}
+	return result
+}
+
+// readPausedDocument 读取被拦截的主文档响应，Content-Length 已超限时不再读取响应体
+func readPausedDocument(page *rod.Page, e *proto.FetchRequestPaused, limits rawBodyLimits) documentResponseResult {
+	header := newFetchHeader(e.ResponseHeaders)
+	result := documentResponseResult{response: newPausedDocumentResponse(e, header, header.Get("Content-Type"))}
+
+	limit := limits.limit()
+	if size, err := strconv.ParseInt(header.Get("Content-Length"), 10, 64); err == nil && size > limit {
+		result.err = newBodyTooLargeError(e.Request.URL, limit)
+		return result
+	}
+
+	stream, err := proto.FetchTakeResponseBodyAsStream{RequestID: e.RequestID}.Call(page)
+	if err != nil {
+		result.err = err
+		return result
+	}
+	reader := rod.NewStreamReader(page, stream.Stream)
+	defer func() {
+		_ = reader.Close()
+	}()
+
+	data, err := io.ReadAll(io.LimitReader(reader, limit+1))
+	if err != nil {
+		result.err = err
+		return result
+	}
+	if int64(len(data)) > limit {
+		result.err = newBodyTooLargeError(e.Request.URL, limit)
+		return result
+	}
+	result.data = data
+
+	// 服务端没有声明 Content-Type 时按内容嗅探，与浏览器的行为保持一致
+	if result.response.Response.MIMEType == "" {
+		result.response.Response.MIMEType = mediaType(http.DetectContentType(data))
+	}
+	if !isAllowedContentType(result.response.Response.MIMEType, limits.contentTypes) {
+		result.err = fmt.Errorf("%w: %s", ErrUnsupportedContentType, result.response.Response.MIMEType)
+	}
+	return result
+}
+
+func newBodyTooLargeError(u string, limit int64) error {
+	return fmt.Errorf("%w: %s exceeds %d bytes", ErrBodyTooLarge, u, limit)
+}
+
+// newPausedDocumentResponse 把拦截到的响应转换成 Network.responseReceived 的形式，便于复用 trace / header 逻辑
+func newPausedDocumentResponse(e *proto.FetchRequestPaused, header http.Header, contentType string) *proto.NetworkResponseReceived {
+	headers := make(proto.NetworkHeaders, len(header))
+	for key, values := range header {
+		headers[key] = gson.New(strings.Join(values, "\n"))
+	}
+
+	return &proto.NetworkResponseReceived{
+		RequestID: e.NetworkID,
+		Type:      e.ResourceType,
+		FrameID:   e.FrameID,
+		Response: &proto.NetworkResponse{
+			URL:        e.Request.URL,
+			Status:     *e.ResponseStatusCode,
+			StatusText: e.ResponseStatusText,
+			Headers:    headers,
+			MIMEType:   mediaType(contentType),
+		},
+	}
+}
+
+func newFetchHeader(entries []*proto.FetchHeaderEntry) http.Header {
+	header := make(http.Header, len(entries))
+	for _, entry := range entries {
+		if entry == nil {
+			continue
+		}
+		header.Add(entry.Name, strings.TrimSpace(entry.Value))
+	}
+	return header
+}
+
+func isRedirectResponse(status int, headers []*proto.FetchHeaderEntry) bool {
+	if status < 300 || status > 399 {
+		return false
+	}
+	return newFetchHeader(headers).Get("Location") != ""
+}
+
+// mediaType 去掉 Content-Type 的参数部分并转为小写
+func mediaType(contentType string) string {
+	if contentType == "" {
+		return ""
+	}
+	if parsed, _, err := mime.ParseMediaType(contentType); err == nil {
+		return parsed
+	}
+	value, _, _ := strings.Cut(contentType, ";")
+	return strings.ToLower(strings.TrimSpace(value))
+}
+
+// isAllowedContentType 判断 mimeType 是否在允许列表中，列表支持 image/* 形式的通配，为空时全部允许
+func isAllowedContentType(mimeType string, allowed []string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	mimeType = mediaType(mimeType)
+	for _, pattern := range allowed {
+		pattern = mediaType(pattern)
+		switch {
+		case pattern == "*/*" || pattern == mimeType:
+			return true
+		case strings.HasSuffix(pattern, "/*") && strings.HasPrefix(mimeType, strings.TrimSuffix(pattern, "*")):
+			return true
+		}
+	}
+	return false
+}
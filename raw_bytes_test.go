@@ -0,0 +1,101 @@
+package pageviewer
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsAllowedContentTypeMatchesWildcards(t *testing.T) {
+	assert.True(t, isAllowedContentType("application/pdf", nil))
+	assert.True(t, isAllowedContentType("application/pdf", []string{"application/PDF"}))
+	assert.True(t, isAllowedContentType("image/png", []string{"application/pdf", "image/*"}))
+	assert.True(t, isAllowedContentType("application/zip", []string{"*/*"}))
+	assert.False(t, isAllowedContentType("text/html", []string{"image/*"}))
+	assert.False(t, isAllowedContentType("imagex/png", []string{"image/*"}))
+}
+
+func TestMediaTypeStripsParameters(t *testing.T) {
+	assert.Equal(t, "text/html", mediaType("Text/HTML; charset=utf-8"))
+	assert.Equal(t, "application/pdf", mediaType("application/pdf;"))
+	assert.Empty(t, mediaType(""))
+}
+
+func TestIsRedirectResponseRequiresLocation(t *testing.T) {
+	location := []*proto.FetchHeaderEntry{{Name: "location", Value: "/next"}}
+	assert.True(t, isRedirectResponse(http.StatusFound, location))
+	assert.False(t, isRedirectResponse(http.StatusNotModified, nil))
+	assert.False(t, isRedirectResponse(http.StatusOK, location))
+}
+
+func TestClientRawBytesReturnsBinaryDocuments(t *testing.T) {
+	pdf, err := os.ReadFile("test/aaa.pdf")
+	require.NoError(t, err)
+	image := []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1a, '\n', 0x00, 0xff, 0xfe, 0x00}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/aaa.pdf", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Header().Set("X-Test", "pdf")
+		_, _ = w.Write(pdf)
+	})
+	mux.HandleFunc("/download", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Disposition", `attachment; filename="image.png"`)
+		_, _ = w.Write(image)
+	})
+	mux.HandleFunc("/moved", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/aaa.pdf", http.StatusFound)
+	})
+	s := httptest.NewServer(mux)
+	defer s.Close()
+
+	client := newTestClient(t, Config{PoolSize: 1, Warmup: 1})
+
+	resp, err := client.RawBytes(context.Background(), s.URL+"/moved")
+	require.NoError(t, err)
+	assert.True(t, bytes.Equal(pdf, resp.Body))
+	assert.Equal(t, "application/pdf", resp.ContentType)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, s.URL+"/aaa.pdf", resp.FinalURL)
+	assert.Equal(t, "pdf", resp.Header.Get("X-Test"))
+
+	resp, err = client.RawBytes(context.Background(), s.URL+"/download", WithAllowedContentTypes("image/*"))
+	require.NoError(t, err)
+	assert.Equal(t, image, resp.Body)
+	assert.Equal(t, "image/png", resp.ContentType)
+
+	_, err = client.RawBytes(context.Background(), s.URL+"/aaa.pdf", WithAllowedContentTypes("image/*"))
+	assert.ErrorIs(t, err, ErrUnsupportedContentType)
+
+	_, err = client.RawBytes(context.Background(), s.URL+"/aaa.pdf", WithMaxBodySize(int64(len(pdf)-1)))
+	assert.ErrorIs(t, err, ErrBodyTooLarge)
+
+	// 拒绝响应后 worker 仍然可用
+	resp, err = client.RawBytes(context.Background(), s.URL+"/aaa.pdf", WithMaxBodySize(int64(len(pdf))))
+	require.NoError(t, err)
+	assert.Len(t, resp.Body, len(pdf))
+}
+
+func TestClientRawBytesReadsFileURLWithoutResponseInterception(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pixel.png")
+	require.NoError(t, os.WriteFile(path, archiveTestPNG, 0o644))
+
+	client := newTestClient(t, Config{PoolSize: 1, Warmup: 1})
+
+	// file:// 的主文档不会在响应阶段暂停，使用生产环境默认的 20s 等待时长时也要立即读取响应体，不能等到超时
+	start := time.Now()
+	resp, err := client.RawBytes(context.Background(), "file://"+path, WithWaitTimeout(20*time.Second))
+	require.NoError(t, err)
+	assert.Less(t, time.Since(start), 5*time.Second)
+	assert.Equal(t, archiveTestPNG, resp.Body)
+	assert.Equal(t, "image/png", resp.ContentType)
+}
@@ -0,0 +1,68 @@
+package pageviewer
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// workerRecycler 按请求数、存活时长和 JS 堆大小回收 worker，并按原因计数
+type workerRecycler struct {
+	maxRequests int
+	maxAge      time.Duration
+	maxHeapSize int64
+
+	byRequests atomic.Int64
+	byAge      atomic.Int64
+	byMemory   atomic.Int64
+}
+
+func newWorkerRecycler(cfg Config) *workerRecycler {
+	return &workerRecycler{
+		maxRequests: cfg.MaxRequestsPerWorker,
+		maxAge:      cfg.MaxWorkerAge,
+		maxHeapSize: cfg.MaxWorkerHeapSize,
+	}
+}
+
+// withLimits 把回收条件写回 cfg，供 NewSession 沿用 Client 的配置
+func (r *workerRecycler) withLimits(cfg Config) Config {
+	if r != nil {
+		cfg.MaxRequestsPerWorker = r.maxRequests
+		cfg.MaxWorkerAge = r.maxAge
+		cfg.MaxWorkerHeapSize = r.maxHeapSize
+	}
+	return cfg
+}
+
+// shouldRecycle 判断 worker 是否达到 Config.MaxRequestsPerWorker、Config.MaxWorkerAge 或 Config.MaxWorkerHeapSize，
+// 达到时按原因计数；回收的 worker 不再归还池，由 scheduleRepair 在后台关闭并补建新的 worker
+func (r *workerRecycler) shouldRecycle(w *worker) bool {
+	if r == nil {
+		return false
+	}
+	switch {
+	case r.maxRequests > 0 && w.requests >= r.maxRequests:
+		r.byRequests.Add(1)
+	case r.maxAge > 0 && !w.createdAt.IsZero() && time.Since(w.createdAt) >= r.maxAge:
+		r.byAge.Add(1)
+	case r.maxHeapSize > 0 && workerHeapSize(w) >= r.maxHeapSize:
+		r.byMemory.Add(1)
+	default:
+		return false
+	}
+	return true
+}
+
+// workerHeapSize 返回 worker 页面已使用的 JS 堆大小，读取失败时返回 0，交给后续的重置和健康检查处理
+func workerHeapSize(w *worker) int64 {
+	if w.page == nil {
+		return 0
+	}
+	usage, err := proto.RuntimeGetHeapUsage{}.Call(w.page)
+	if err != nil {
+		return 0
+	}
+	return int64(usage.UsedSize)
+}
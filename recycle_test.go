@@ -0,0 +1,73 @@
+package pageviewer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkerRecyclerShouldRecycle(t *testing.T) {
+	var nilRecycler *workerRecycler
+	assert.False(t, nilRecycler.shouldRecycle(&worker{requests: 100}))
+
+	recycler := newWorkerRecycler(Config{MaxRequestsPerWorker: 2, MaxWorkerAge: time.Minute})
+	assert.False(t, recycler.shouldRecycle(&worker{requests: 1, createdAt: time.Now()}))
+	assert.True(t, recycler.shouldRecycle(&worker{requests: 2, createdAt: time.Now()}))
+	assert.True(t, recycler.shouldRecycle(&worker{requests: 1, createdAt: time.Now().Add(-2 * time.Minute)}))
+	// 没有创建时间的 worker 不按存活时长回收
+	assert.False(t, recycler.shouldRecycle(&worker{requests: 1}))
+	assert.EqualValues(t, 1, recycler.byRequests.Load())
+	assert.EqualValues(t, 1, recycler.byAge.Load())
+	assert.Zero(t, recycler.byMemory.Load())
+
+	cfg := Config{MaxRequestsPerWorker: -1, MaxWorkerAge: -time.Second, MaxWorkerHeapSize: -1}.withDefaults()
+	assert.Zero(t, cfg.MaxRequestsPerWorker)
+	assert.Zero(t, cfg.MaxWorkerAge)
+	assert.Zero(t, cfg.MaxWorkerHeapSize)
+}
+
+func TestClientRecyclesWorkerAfterMaxRequests(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(`<html><body>recycle</body></html>`))
+	}))
+	defer s.Close()
+
+	client := newTestClient(t, Config{PoolSize: 1, Warmup: 1, MaxRequestsPerWorker: 2})
+	ctx := context.Background()
+	targetID := func() proto.TargetTargetID {
+		client.mu.Lock()
+		defer client.mu.Unlock()
+		if len(client.workers) == 0 || client.workers[0].page == nil {
+			return ""
+		}
+		return client.workers[0].page.TargetID
+	}
+	original := targetID()
+	require.NotEmpty(t, original)
+
+	for i := 0; i < 2; i++ {
+		html, err := client.HTML(ctx, s.URL)
+		require.NoError(t, err)
+		assert.Contains(t, html, "recycle")
+	}
+
+	require.Eventually(t, func() bool {
+		id := targetID()
+		return id != "" && id != original
+	}, 10*time.Second, 50*time.Millisecond)
+	stats := client.Stats()
+	assert.EqualValues(t, 1, stats.RecycledByRequests)
+	assert.Equal(t, 1, stats.TotalWorkers)
+
+	html, err := client.HTML(ctx, s.URL)
+	require.NoError(t, err)
+	assert.Contains(t, html, "recycle")
+	assert.EqualValues(t, 1, client.Stats().RecycledByRequests)
+}
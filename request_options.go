@@ -1,17 +1,39 @@
 package pageviewer
 
 import (
+	"io"
+	"net/http"
 	"time"
 
 	"github.com/go-rod/rod"
 )
 
 type RequestOptions struct {
-	WaitTimeout        time.Duration
-	AcquireTimeout     time.Duration
-	BeforeRequest      func(page *rod.Page) error
-	RemoveInvisibleDiv bool
-	TraceID            string
+	WaitTimeout         time.Duration
+	AcquireTimeout      time.Duration
+	BeforeRequest       func(page *rod.Page) error
+	RemoveInvisibleDiv  bool
+	TraceID             string
+	WARC                *WARCWriter
+	NetworkLog          *HAR
+	MaxBodySize         int64
+	AllowedContentTypes []string
+	UndecodedBody       bool
+	WaitFor             []WaitCondition
+	WaitStrategy        WaitStrategy
+	Actions             []Action
+	AutoScroll          *AutoScrollOptions
+	DialogPolicy        DialogPolicy
+	FollowPopup         bool
+	Headers             http.Header
+	UserAgent           string
+	AcceptLanguage      string
+	Device              *DeviceProfile
+	Locale              string
+	Timezone            string
+	Geolocation         *Geolocation
+	Cookies             []*http.Cookie
+	IsolatedContext     bool
 
 	browser *Browser
 }
@@ -32,13 +54,187 @@ func WithAcquireTimeout(timeout time.Duration) RequestOption {
 	}
 }
 
+// WithWARC 把本次请求的所有网络请求 / 响应作为 gzip 压缩的 WARC 记录写入 w
+func WithWARC(w io.Writer) RequestOption {
+	return func(vo *VisitOptions) {
+		vo.warc = nil
+		if w != nil {
+			vo.warc = NewWARCWriter(w, true)
+		}
+	}
+}
+
+// WithNetworkLog 记录本次请求的网络事件，请求结束后以 HAR 1.2 写入 har 并挂到 Trace.NetworkLog
+func WithNetworkLog(har *HAR) RequestOption {
+	return func(vo *VisitOptions) {
+		h := har
+		if h == nil {
+			h = &HAR{}
+		}
+		vo.networkLog = h
+	}
+}
+
+// WithMaxBodySize 限制 RawBytes 读取的响应体字节数
+func WithMaxBodySize(n int64) RequestOption {
+	return func(vo *VisitOptions) {
+		vo.maxBodySize = n
+	}
+}
+
+// WithAllowedContentTypes 限制 RawBytes 接受的响应类型
+func WithAllowedContentTypes(types ...string) RequestOption {
+	return func(vo *VisitOptions) {
+		vo.contentTypes = append([]string(nil), types...)
+	}
+}
+
+// WithUndecodedBody 让 RawText 额外在 TextResponse.RawBody 中返回转码前的原始字节
+func WithUndecodedBody(enabled bool) RequestOption {
+	return func(vo *VisitOptions) {
+		vo.undecodedBody = enabled
+	}
+}
+
+// WithWaitFor 在默认的稳定等待之后继续等待全部条件满足再抽取
+func WithWaitFor(conditions ...WaitCondition) RequestOption {
+	return func(vo *VisitOptions) {
+		vo.PageOptions.waitFor = append(vo.PageOptions.waitFor, conditions...)
+	}
+}
+
+// WithWaitStrategy 设置本次请求的稳定等待策略，覆盖 Config.WaitStrategy
+func WithWaitStrategy(strategy WaitStrategy) RequestOption {
+	return func(vo *VisitOptions) {
+		vo.PageOptions.waitStrategy = strategy
+	}
+}
+
+// WithActions 在抽取前依次执行交互动作
+func WithActions(actions ...Action) RequestOption {
+	return func(vo *VisitOptions) {
+		vo.PageOptions.actions = append(vo.PageOptions.actions, actions...)
+	}
+}
+
+// WithAutoScroll 在抽取前滚动到页面高度不再增长并加载懒加载内容
+func WithAutoScroll(opts AutoScrollOptions) RequestOption {
+	return func(vo *VisitOptions) {
+		vo.PageOptions.autoScroll = &opts
+	}
+}
+
+// WithDialogPolicy 设置本次请求 JS 对话框的处理策略，覆盖 Config.DialogPolicy
+func WithDialogPolicy(policy DialogPolicy) RequestOption {
+	return func(vo *VisitOptions) {
+		vo.dialogPolicy = policy
+	}
+}
+
+// WithFollowPopup 在页面打开的最近一个新页面上等待并抽取
+func WithFollowPopup(enabled bool) RequestOption {
+	return func(vo *VisitOptions) {
+		vo.followPopup = enabled
+	}
+}
+
+// WithHeaders 为本次请求附加额外的请求头
+func WithHeaders(header http.Header) RequestOption {
+	return func(vo *VisitOptions) {
+		if vo.headers == nil {
+			vo.headers = make(http.Header, len(header))
+		}
+		for name, values := range header {
+			for _, value := range values {
+				vo.headers.Add(name, value)
+			}
+		}
+	}
+}
+
+// WithUserAgent 覆盖本次请求的 User-Agent
+func WithUserAgent(userAgent string) RequestOption {
+	return func(vo *VisitOptions) {
+		vo.userAgent = userAgent
+	}
+}
+
+// WithAcceptLanguage 覆盖本次请求的 Accept-Language
+func WithAcceptLanguage(acceptLanguage string) RequestOption {
+	return func(vo *VisitOptions) {
+		vo.acceptLanguage = acceptLanguage
+	}
+}
+
+// WithDevice 模拟本次请求的设备
+func WithDevice(device DeviceProfile) RequestOption {
+	return func(vo *VisitOptions) {
+		vo.device = &device
+	}
+}
+
+// WithLocale 覆盖本次请求的 ICU 语言区域
+func WithLocale(locale string) RequestOption {
+	return func(vo *VisitOptions) {
+		vo.locale = locale
+	}
+}
+
+// WithTimezone 覆盖本次请求的时区
+func WithTimezone(timezone string) RequestOption {
+	return func(vo *VisitOptions) {
+		vo.timezone = timezone
+	}
+}
+
+// WithGeolocation 覆盖本次请求的地理位置
+func WithGeolocation(latitude, longitude, accuracy float64) RequestOption {
+	return func(vo *VisitOptions) {
+		vo.geolocation = &Geolocation{Latitude: latitude, Longitude: longitude, Accuracy: accuracy}
+	}
+}
+
+// WithCookies 在导航前写入本次请求使用的 cookie
+func WithCookies(cookies ...*http.Cookie) RequestOption {
+	return func(vo *VisitOptions) {
+		vo.cookies = append(vo.cookies, cookies...)
+	}
+}
+
+// WithIsolatedContext 在独立的浏览器上下文中执行本次请求
+func WithIsolatedContext() RequestOption {
+	return func(vo *VisitOptions) {
+		vo.isolated = true
+	}
+}
+
 func (vo *VisitOptions) toRequestOptions() RequestOptions {
 	return RequestOptions{
-		WaitTimeout:        vo.PageOptions.waitTimeout,
-		AcquireTimeout:     vo.acquireTimeout,
-		BeforeRequest:      vo.PageOptions.beforeRequest,
-		RemoveInvisibleDiv: vo.PageOptions.removeInvisibleDiv,
-		TraceID:            vo.traceID,
-		browser:            vo.browser,
+		WaitTimeout:         vo.PageOptions.waitTimeout,
+		AcquireTimeout:      vo.acquireTimeout,
+		BeforeRequest:       vo.PageOptions.beforeRequest,
+		RemoveInvisibleDiv:  vo.PageOptions.removeInvisibleDiv,
+		TraceID:             vo.traceID,
+		WARC:                vo.warc,
+		NetworkLog:          vo.networkLog,
+		MaxBodySize:         vo.maxBodySize,
+		AllowedContentTypes: vo.contentTypes,
+		UndecodedBody:       vo.undecodedBody,
+		WaitFor:             vo.PageOptions.waitFor,
+		WaitStrategy:        vo.PageOptions.waitStrategy,
+		Actions:             vo.PageOptions.actions,
+		AutoScroll:          vo.PageOptions.autoScroll,
+		DialogPolicy:        vo.dialogPolicy,
+		FollowPopup:         vo.followPopup,
+		Headers:             vo.headers,
+		UserAgent:           vo.userAgent,
+		AcceptLanguage:      vo.acceptLanguage,
+		Device:              vo.device,
+		Locale:              vo.locale,
+		Timezone:            vo.timezone,
+		Geolocation:         vo.geolocation,
+		Cookies:             vo.cookies,
+		IsolatedContext:     vo.isolated,
+		browser:             vo.browser,
 	}
 }
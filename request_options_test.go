@@ -1,6 +1,7 @@
 package pageviewer
 
 import (
+	"net/http"
 	"testing"
 	"time"
 
@@ -75,3 +76,44 @@ func TestCustomVisitOptionStillWorks(t *testing.T) {
 	assert.Equal(t, time.Second, requestOptions.WaitTimeout)
 	assert.Same(t, expectedBrowser, requestOptions.browser)
 }
+
+func TestRequestOptionsKeepRawBytesLimits(t *testing.T) {
+	types := []string{"application/pdf"}
+	opts := NewRequestOptions(WithMaxBodySize(1024), WithAllowedContentTypes(types...))
+	types[0] = "text/html"
+	assert.Equal(t, int64(1024), opts.MaxBodySize)
+	assert.Equal(t, []string{"application/pdf"}, opts.AllowedContentTypes)
+}
+
+func TestRequestOptionsAppendWaitConditions(t *testing.T) {
+	opts := NewRequestOptions(WithWaitFor(WaitVisible("#app")), WithWaitFor(WaitJS("window.ready")))
+	require.Len(t, opts.WaitFor, 2)
+	assert.Equal(t, `visible("#app")`, opts.WaitFor[0].String())
+	assert.Equal(t, `js("window.ready")`, opts.WaitFor[1].String())
+}
+
+func TestWithNetworkLogNilAllocatesPerRequest(t *testing.T) {
+	opt := WithNetworkLog(nil)
+	first := NewRequestOptions(opt)
+	second := NewRequestOptions(opt)
+	require.NotNil(t, first.NetworkLog)
+	require.NotNil(t, second.NetworkLog)
+	assert.NotSame(t, first.NetworkLog, second.NetworkLog)
+}
+
+func TestWithHeadersMergesValues(t *testing.T) {
+	header := http.Header{"X-Token": {"a"}}
+	opts := NewRequestOptions(WithHeaders(header), WithHeaders(http.Header{"X-Token": {"b"}}), WithUserAgent("ua"), WithAcceptLanguage("en"))
+	header.Set("X-Token", "changed")
+	assert.Equal(t, []string{"a", "b"}, opts.Headers.Values("X-Token"))
+	assert.Equal(t, "ua", opts.UserAgent)
+	assert.Equal(t, "en", opts.AcceptLanguage)
+}
+
+func TestRequestOptionsKeepRegionOverrides(t *testing.T) {
+	opts := NewRequestOptions(WithLocale("ja-JP"), WithTimezone("Asia/Tokyo"), WithGeolocation(35.68, 139.69, 10))
+
+	assert.Equal(t, "ja-JP", opts.Locale)
+	assert.Equal(t, "Asia/Tokyo", opts.Timezone)
+	assert.Equal(t, &Geolocation{Latitude: 35.68, Longitude: 139.69, Accuracy: 10}, opts.Geolocation)
+}
@@ -0,0 +1,79 @@
+package pageviewer
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/devices"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+var workerResetTimeout = 10 * time.Second
+
+// clearStorageTypes ClearStorage 清除的存储类型，不包含 cookie，cookie 由 Cookies / SetCookies 统一管理
+const clearStorageTypes = "local_storage,indexeddb,cache_storage,service_workers,websql,file_systems"
+
+// WorkerResetOptions 归还 worker 前的状态重置配置，零值表示导航到 about:blank、停止遗留的请求拦截并恢复默认的请求头和设备模拟
+type WorkerResetOptions struct {
+	Disabled     bool // 不重置，下一个借用者会看到上一个请求留下的页面
+	ClearStorage bool // 同时清除请求 URL 和最终页面所在来源的 localStorage、sessionStorage、IndexedDB、Cache Storage 和 Service Worker
+}
+
+// resetWorkerPage 把 worker 页面恢复为干净状态，下一个借用者看不到上一个请求的 DOM、JS 全局变量和请求级覆盖项；
+// origins 为需要清除存储的来源，只在 ClearStorage 时使用
+func resetWorkerPage(page *rod.Page, baseline devices.Device, opts WorkerResetOptions, origins []string) error {
+	page = page.Timeout(workerResetTimeout)
+	defer page.CancelTimeout()
+
+	// 回调中遗留的 HijackRequests 路由器已随请求页面的 context 停止，关闭 Fetch 拦截避免后续请求被暂停后无人处理
+	errs := []error{proto.FetchDisable{}.Call(page)}
+	if opts.ClearStorage {
+		_, err := page.Eval(`() => { try { sessionStorage.clear() } catch (e) {} }`)
+		errs = append(errs, err)
+	}
+	errs = append(errs, page.Navigate("about:blank"))
+	if opts.ClearStorage {
+		for _, origin := range origins {
+			errs = append(errs, proto.StorageClearDataForOrigin{Origin: origin, StorageTypes: clearStorageTypes}.Call(page))
+		}
+	}
+
+	errs = append(errs,
+		proto.NetworkSetExtraHTTPHeaders{Headers: proto.NetworkHeaders{}}.Call(page),
+		resetUserAgent(page, baseline),
+		page.SetViewport(baseline.MetricsEmulation()),
+		proto.EmulationSetEmulatedMedia{}.Call(page),
+	)
+	return errors.Join(errs...)
+}
+
+// requestOrigins 返回请求 URL 和页面当前 URL 的来源，去重并忽略 about:blank 等没有来源的地址
+func requestOrigins(urls ...string) []string {
+	var origins []string
+	seen := make(map[string]bool, len(urls))
+	for _, raw := range urls {
+		u, err := url.Parse(raw)
+		if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+			continue
+		}
+		origin := u.Scheme + "://" + u.Host
+		if !seen[origin] {
+			seen[origin] = true
+			origins = append(origins, origin)
+		}
+	}
+	return origins
+}
+
+// requestPage 返回绑定到请求生命周期的页面，交给回调和导航使用；请求结束时取消，
+// 回调中通过该页面创建的 HijackRequests 路由器等事件监听会随之停止
+func requestPage(page *rod.Page) (*rod.Page, context.CancelFunc) {
+	if page == nil {
+		return nil, func() {}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return page.Context(ctx), cancel
+}
@@ -0,0 +1,96 @@
+package pageviewer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestOrigins(t *testing.T) {
+	assert.Equal(t, []string{"https://example.com", "http://127.0.0.1:8080"}, requestOrigins(
+		"https://example.com/a",
+		"https://example.com/b?c=d",
+		"http://127.0.0.1:8080/",
+		"about:blank",
+		"data:text/html,hi",
+		"",
+	))
+}
+
+func TestClientResetsWorkerBetweenRequests(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(`<html><body><p id="v">reset</p></body></html>`))
+	}))
+	defer s.Close()
+
+	client := newTestClient(t, Config{PoolSize: 1, Warmup: 1, WorkerReset: WorkerResetOptions{ClearStorage: true}})
+	ctx := context.Background()
+
+	// 遗留的拦截路由器会让后续请求全部失败，重置后应当不再生效
+	_, err := client.HTML(ctx, s.URL, WithActions(ActionEval(`() => { localStorage.setItem('k', 'v'); sessionStorage.setItem('k', 'v') }`)), WithBeforeRequest(func(page *rod.Page) error {
+		router := page.HijackRequests()
+		router.MustAdd("*/blocked", func(h *rod.Hijack) {
+			h.Response.Fail(proto.NetworkErrorReasonBlockedByClient)
+		})
+		router.MustAdd("*", func(h *rod.Hijack) {
+			h.ContinueRequest(&proto.FetchContinueRequest{})
+		})
+		go router.Run()
+		return nil
+	}), WithTraceID("reset-hijack"))
+	require.NoError(t, err)
+
+	trace, ok := client.DebugTrace("reset-hijack")
+	require.True(t, ok)
+	assert.Positive(t, trace.ResetDuration)
+	assert.False(t, trace.BrokenWorker)
+
+	html, err := client.HTML(ctx, s.URL+"/blocked")
+	require.NoError(t, err)
+	assert.Contains(t, html, "reset")
+
+	client.mu.Lock()
+	page := client.workers[0].page
+	client.mu.Unlock()
+	info, err := page.Info()
+	require.NoError(t, err)
+	assert.Equal(t, "about:blank", info.URL)
+
+	var stored string
+	err = client.Visit(ctx, s.URL, func(page *rod.Page) error {
+		stored = page.MustEval(`() => (localStorage.getItem('k') || '') + (sessionStorage.getItem('k') || '')`).Str()
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Empty(t, stored)
+}
+
+func TestClientWorkerResetDisabled(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(`<html><body>kept</body></html>`))
+	}))
+	defer s.Close()
+
+	client := newTestClient(t, Config{PoolSize: 1, Warmup: 1, WorkerReset: WorkerResetOptions{Disabled: true}})
+	_, err := client.HTML(context.Background(), s.URL, WithTraceID("reset-disabled"))
+	require.NoError(t, err)
+
+	trace, ok := client.DebugTrace("reset-disabled")
+	require.True(t, ok)
+	assert.Zero(t, trace.ResetDuration)
+
+	client.mu.Lock()
+	page := client.workers[0].page
+	client.mu.Unlock()
+	info, err := page.Info()
+	require.NoError(t, err)
+	assert.Contains(t, info.URL, s.URL)
+}
@@ -0,0 +1,136 @@
+package pageviewer
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+type ScreenshotFormat string
+
+const (
+	ScreenshotFormatPNG  ScreenshotFormat = "png"
+	ScreenshotFormatJPEG ScreenshotFormat = "jpeg"
+	ScreenshotFormatWebP ScreenshotFormat = "webp"
+)
+
+// ScreenshotOptions 截图配置项
+type ScreenshotOptions struct {
+	Format   ScreenshotFormat // 图片格式，默认 png
+	Quality  int              // 压缩质量 0-100，只对 jpeg / webp 生效，png 设置时返回错误
+	FullPage bool             // 是否截取整个可滚动页面，默认只截取当前视口
+	Selector string           // 只截取匹配 CSS 选择器的第一个元素
+	Clip     *ScreenshotClip  // 截取指定区域，坐标相对于整个页面
+}
+
+// ScreenshotClip 截图区域，单位为 CSS 像素
+type ScreenshotClip struct {
+	X      float64
+	Y      float64
+	Width  float64
+	Height float64
+}
+
+func (so ScreenshotOptions) withDefaults() (ScreenshotOptions, error) {
+	switch so.Format {
+	case "":
+		so.Format = ScreenshotFormatPNG
+	case ScreenshotFormatPNG, ScreenshotFormatJPEG, ScreenshotFormatWebP:
+	default:
+		return so, fmt.Errorf("pageviewer: unsupported screenshot format: %s", so.Format)
+	}
+	if so.Quality < 0 || so.Quality > 100 {
+		return so, fmt.Errorf("pageviewer: invalid screenshot quality: %d", so.Quality)
+	}
+	if so.Quality > 0 && so.Format == ScreenshotFormatPNG {
+		return so, fmt.Errorf("pageviewer: screenshot quality is not supported for png")
+	}
+	if so.Selector != "" && so.Clip != nil {
+		return so, fmt.Errorf("pageviewer: screenshot selector and clip are mutually exclusive")
+	}
+	if so.FullPage && (so.Selector != "" || so.Clip != nil) {
+		return so, fmt.Errorf("pageviewer: screenshot full page and selector or clip are mutually exclusive")
+	}
+	return so, nil
+}
+
+// Screenshot 获取渲染后页面的截图，支持视口、整页、单个元素或指定区域
+func (c *Client) Screenshot(ctx context.Context, url string, so ScreenshotOptions, opts ...RequestOption) ([]byte, error) {
+	so, err := so.withDefaults()
+	if err != nil {
+		return nil, err
+	}
+
+	var data []byte
+	err = c.visitWithOptions(ctx, url, NewRequestOptions(opts...), true, func(page *rod.Page, _ *proto.NetworkResponseReceived) error {
+		var err error
+		data, err = captureScreenshot(page, so)
+		return err
+	})
+	return data, err
+}
+
+func captureScreenshot(page *rod.Page, so ScreenshotOptions) ([]byte, error) {
+	req := &proto.PageCaptureScreenshot{
+		Format: proto.PageCaptureScreenshotFormat(so.Format),
+	}
+	if so.Quality > 0 {
+		quality := so.Quality
+		req.Quality = &quality
+	}
+
+	clip := so.Clip
+	if so.Selector != "" {
+		var err error
+		if clip, err = elementClip(page, so.Selector); err != nil {
+			return nil, err
+		}
+	}
+	if clip != nil {
+		req.Clip = &proto.PageViewport{
+			X:      clip.X,
+			Y:      clip.Y,
+			Width:  clip.Width,
+			Height: clip.Height,
+			Scale:  1,
+		}
+		req.CaptureBeyondViewport = true
+		return page.Screenshot(false, req)
+	}
+
+	return page.Screenshot(so.FullPage, req)
+}
+
+func elementClip(page *rod.Page, selector string) (*ScreenshotClip, error) {
+	elements, err := page.Elements(selector)
+	if err != nil {
+		return nil, err
+	}
+	if len(elements) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrElementNotFound, selector)
+	}
+
+	r, err := elements[0].Eval(`() => {
+		const rect = this.getBoundingClientRect();
+		return {
+			X: rect.left + window.scrollX,
+			Y: rect.top + window.scrollY,
+			Width: rect.width,
+			Height: rect.height,
+		};
+	}`)
+	if err != nil {
+		return nil, err
+	}
+
+	var clip ScreenshotClip
+	if err := r.Value.Unmarshal(&clip); err != nil {
+		return nil, err
+	}
+	if clip.Width <= 0 || clip.Height <= 0 {
+		return nil, fmt.Errorf("%w: %s has an empty box", ErrElementNotFound, selector)
+	}
+	return &clip, nil
+}
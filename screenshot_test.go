@@ -0,0 +1,74 @@
+package pageviewer
+
+import (
+	"bytes"
+	"context"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScreenshotOptionsDefaultsAndValidation(t *testing.T) {
+	so, err := ScreenshotOptions{}.withDefaults()
+	require.NoError(t, err)
+	assert.Equal(t, ScreenshotFormatPNG, so.Format)
+
+	_, err = ScreenshotOptions{Format: "gif"}.withDefaults()
+	assert.ErrorContains(t, err, "unsupported screenshot format")
+
+	_, err = ScreenshotOptions{Quality: 101}.withDefaults()
+	assert.ErrorContains(t, err, "invalid screenshot quality")
+
+	_, err = ScreenshotOptions{Selector: "#app", Clip: &ScreenshotClip{Width: 1, Height: 1}}.withDefaults()
+	assert.ErrorContains(t, err, "mutually exclusive")
+
+	_, err = ScreenshotOptions{Quality: 80}.withDefaults()
+	assert.ErrorContains(t, err, "not supported for png")
+
+	_, err = ScreenshotOptions{FullPage: true, Selector: "#app"}.withDefaults()
+	assert.ErrorContains(t, err, "mutually exclusive")
+
+	_, err = ScreenshotOptions{FullPage: true, Clip: &ScreenshotClip{Width: 1, Height: 1}}.withDefaults()
+	assert.ErrorContains(t, err, "mutually exclusive")
+}
+
+func TestClientScreenshotCapturesViewportAndElement(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><body style="margin:0"><div id="box" style="margin-top:1500px;width:120px;height:80px;background:red"></div></body></html>`))
+	}))
+	defer s.Close()
+
+	client := newTestClient(t, Config{PoolSize: 1, Warmup: 1})
+
+	data, err := client.Screenshot(context.Background(), s.URL, ScreenshotOptions{})
+	require.NoError(t, err)
+	_, err = png.Decode(bytes.NewReader(data))
+	require.NoError(t, err)
+
+	data, err = client.Screenshot(context.Background(), s.URL, ScreenshotOptions{Selector: "#box"})
+	require.NoError(t, err)
+	img, err := png.Decode(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, 120, img.Bounds().Dx())
+	assert.Equal(t, 80, img.Bounds().Dy())
+
+	data, err = client.Screenshot(context.Background(), s.URL, ScreenshotOptions{Format: ScreenshotFormatJPEG, Quality: 50, FullPage: true})
+	require.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(data, []byte{0xff, 0xd8}))
+}
+
+func TestClientScreenshotMissingSelector(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><body><div id="app">ok</div></body></html>`))
+	}))
+	defer s.Close()
+
+	client := newTestClient(t, Config{PoolSize: 1, Warmup: 1})
+
+	_, err := client.Screenshot(context.Background(), s.URL, ScreenshotOptions{Selector: "#missing"})
+	assert.ErrorIs(t, err, ErrElementNotFound)
+}
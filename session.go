@@ -0,0 +1,142 @@
+package pageviewer
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// SessionOptions NewSession 的配置，零值字段使用对应的默认值
+type SessionOptions struct {
+	PoolSize        int            // 会话内的 worker 数，默认 1
+	Warmup          int            // 创建会话时预热的 worker 数，默认 1 且不超过 PoolSize
+	Proxy           string         // 会话专用代理，例如 http://127.0.0.1:8080，为空时沿用浏览器的代理
+	ProxyBypassList string         // 不走代理的主机列表，例如 localhost,*.internal
+	Cookies         []*http.Cookie // 会话创建后写入的初始 cookie
+}
+
+// Session 运行在独立浏览器上下文中的会话
+type Session struct {
+	*Client
+	parent *Client
+}
+
+// NewSession 新建独立浏览器上下文中的会话
+func (c *Client) NewSession(ctx context.Context, opts SessionOptions) (*Session, error) {
+	if err := c.beginTrackedOperation(); err != nil {
+		return nil, err
+	}
+	defer c.inflight.Done()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if c.browser == nil {
+		return nil, ErrBrowserUnavailable
+	}
+
+	browser, err := c.browser.newIsolatedContext(opts.Proxy, opts.ProxyBypassList)
+	if err != nil {
+		return nil, err
+	}
+	cfg := Config{
+		PoolSize:       opts.PoolSize,
+		Warmup:         opts.Warmup,
+		AcquireTimeout: c.acquireTimeout,
+		WaitStrategy:   c.waitStrategy,
+		DialogPolicy:   c.dialogPolicy,
+		WorkerReset:    c.workerReset,
+	}
+	cfg = c.recycler.withLimits(cfg).withDefaults()
+	client, err := startPool(ctx, browser, cfg, c.traces)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(opts.Cookies) > 0 {
+		if err := client.SetCookies(ctx, opts.Cookies); err != nil {
+			return nil, errors.Join(err, client.Close())
+		}
+	}
+
+	session := &Session{Client: client, parent: c}
+	c.mu.Lock()
+	c.sessions = append(c.sessions, session)
+	c.mu.Unlock()
+	return session, nil
+}
+
+// Close 关闭会话的 worker 并销毁浏览器上下文，上下文中的 cookie 和存储一并丢弃
+func (s *Session) Close() error {
+	if s == nil || s.Client == nil {
+		return nil
+	}
+
+	if s.parent != nil {
+		s.parent.mu.Lock()
+		for i, session := range s.parent.sessions {
+			if session == s {
+				s.parent.sessions = append(s.parent.sessions[:i], s.parent.sessions[i+1:]...)
+				break
+			}
+		}
+		s.parent.mu.Unlock()
+	}
+	return s.Client.Close()
+}
+
+// newIsolatedContext 新建独立的浏览器上下文，proxy 为空时沿用浏览器的代理；
+// 返回的 Browser 在该上下文中创建页面，Close 时销毁上下文
+func (b *Browser) newIsolatedContext(proxy, proxyBypassList string) (*Browser, error) {
+	if b == nil || b.Browser == nil {
+		return nil, ErrBrowserUnavailable
+	}
+
+	res, err := proto.TargetCreateBrowserContext{ProxyServer: proxy, ProxyBypassList: proxyBypassList}.Call(b.Browser)
+	if err != nil {
+		return nil, err
+	}
+
+	contextBrowser := *b.Browser
+	contextBrowser.BrowserContextID = res.BrowserContextID
+	return &Browser{
+		UseUserMode: b.UseUserMode,
+		Browser:     &contextBrowser,
+		closeFn: func() error {
+			return proto.TargetDisposeBrowserContext{BrowserContextID: res.BrowserContextID}.Call(b.Browser)
+		},
+	}, nil
+}
+
+// acquireWorker 从 worker 池借用 worker；请求设置了 WithIsolatedContext 时改为在新建的浏览器上下文中创建临时 worker，
+// 归还时关闭页面并销毁上下文，不论 worker 状态如何都不会回到池中
+func (c *Client) acquireWorker(ctx context.Context, acquireCtx context.Context, ro RequestOptions) (*worker, func(workerState), error) {
+	timeout := c.acquireWorkerTimeout(ctx, ro)
+	if !ro.IsolatedContext {
+		return c.pool.acquire(acquireCtx, timeout)
+	}
+	if timeout <= 0 {
+		return nil, nil, ErrAcquireTimeout
+	}
+
+	browser, err := c.browser.newIsolatedContext("", "")
+	if err != nil {
+		return nil, nil, err
+	}
+	provisionCtx, cancel := context.WithTimeout(acquireCtx, timeout)
+	defer cancel()
+	w, err := newClientWorker(provisionCtx, browser, c.allocateWorkerID())
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) && acquireCtx.Err() == nil {
+			err = ErrAcquireTimeout
+		}
+		return nil, nil, errors.Join(err, browser.Close())
+	}
+
+	return w, func(workerState) {
+		_ = w.close()
+		_ = browser.Close()
+	}, nil
+}
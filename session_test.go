@@ -0,0 +1,130 @@
+package pageviewer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-rod/rod"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSessionAfterCloseReturnsErrClosed(t *testing.T) {
+	client := &Client{closeCh: make(chan struct{})}
+	require.NoError(t, client.Close())
+
+	_, err := client.NewSession(context.Background(), SessionOptions{})
+	assert.ErrorIs(t, err, ErrClosed)
+}
+
+func TestClientSessionsIsolateState(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(`<html><body>session</body></html>`))
+	}))
+	defer s.Close()
+
+	client := newTestClient(t, Config{PoolSize: 1, Warmup: 1})
+	ctx := context.Background()
+
+	tenantA, err := client.NewSession(ctx, SessionOptions{Cookies: []*http.Cookie{{Name: "tenant", Value: "a", Domain: "127.0.0.1"}}})
+	require.NoError(t, err)
+	tenantB, err := client.NewSession(ctx, SessionOptions{})
+	require.NoError(t, err)
+	defer tenantB.Close()
+
+	state := func(c *Client, opts ...RequestOption) (string, string) {
+		var cookie, stored string
+		err := c.Visit(ctx, s.URL, func(page *rod.Page) error {
+			cookie = page.MustEval(`() => document.cookie`).Str()
+			stored = page.MustEval(`() => localStorage.getItem('tenant') || ''`).Str()
+			return nil
+		}, opts...)
+		require.NoError(t, err)
+		return cookie, stored
+	}
+
+	require.NoError(t, tenantA.Visit(ctx, s.URL, func(page *rod.Page) error {
+		_, err := page.Eval(`() => localStorage.setItem('tenant', 'a')`)
+		return err
+	}, WithTraceID("session-a")))
+	cookie, stored := state(tenantA.Client)
+	assert.Equal(t, "tenant=a", cookie)
+	assert.Equal(t, "a", stored)
+
+	cookie, stored = state(tenantB.Client)
+	assert.Empty(t, cookie)
+	assert.Empty(t, stored)
+
+	cookie, stored = state(client)
+	assert.Empty(t, cookie)
+	assert.Empty(t, stored)
+
+	// 会话的 trace 记录在所属 Client 中
+	_, ok := client.DebugTrace("session-a")
+	assert.True(t, ok)
+
+	require.NoError(t, tenantA.Close())
+	_, err = tenantA.HTML(ctx, s.URL)
+	assert.ErrorIs(t, err, ErrClosed)
+	assert.Len(t, client.sessions, 1)
+}
+
+func TestClientWithIsolatedContext(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(`<html><body>isolated</body></html>`))
+	}))
+	defer s.Close()
+
+	client := newTestClient(t, Config{PoolSize: 1, Warmup: 1})
+	ctx := context.Background()
+	require.NoError(t, client.SetCookies(ctx, []*http.Cookie{{Name: "shared", Value: "1", Domain: "127.0.0.1"}}))
+	defer client.ClearCookies(ctx)
+
+	var cookie string
+	err := client.Visit(ctx, s.URL, func(page *rod.Page) error {
+		cookie = page.MustEval(`() => document.cookie`).Str()
+		page.MustEval(`() => { document.cookie = 'leak=1'; }`)
+		return nil
+	}, WithIsolatedContext())
+	require.NoError(t, err)
+	assert.Empty(t, cookie)
+
+	cookies, err := client.Cookies(ctx, CookieFilter{Name: "leak"})
+	require.NoError(t, err)
+	assert.Empty(t, cookies)
+	assert.Equal(t, 1, client.Stats().TotalWorkers)
+
+	text, err := client.RawText(ctx, s.URL, WithIsolatedContext())
+	require.NoError(t, err)
+	assert.Contains(t, text.Body, "isolated")
+}
+
+func TestClientWithIsolatedContextBrokenWorkerNotRepaired(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(`<html><body>isolated</body></html>`))
+	}))
+	defer s.Close()
+
+	client := newTestClient(t, Config{PoolSize: 1, Warmup: 1})
+	ctx := context.Background()
+
+	// 关闭临时页面让 worker 损坏，临时 worker 只在 release 中关闭一次，不会进入修复流程
+	err := client.Visit(ctx, s.URL, func(page *rod.Page) error {
+		return page.Close()
+	}, WithIsolatedContext(), WithTraceID("isolated-broken"))
+	require.NoError(t, err)
+
+	trace, ok := client.DebugTrace("isolated-broken")
+	require.True(t, ok)
+	assert.True(t, trace.BrokenWorker)
+
+	assert.Equal(t, 1, client.Stats().TotalWorkers)
+	html, err := client.HTML(ctx, s.URL)
+	require.NoError(t, err)
+	assert.Contains(t, html, "isolated")
+}
@@ -0,0 +1,88 @@
+package pageviewer
+
+import (
+	"context"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// SnapshotOptions 控制 Snapshot 在一次页面加载中收集哪些结果，全部为 false 时收集全部结果
+type SnapshotOptions struct {
+	HTML     bool
+	Links    bool
+	Article  bool
+	RawText  bool
+	Metadata bool
+
+	LinkOptions LinkListOptions // 只作用于 Snapshot.LinkList，Links 字符串始终包含全部链接
+}
+
+// Snapshot 一次页面加载得到的多种结果
+type Snapshot struct {
+	HTML     string
+	Links    string
+	LinkList []Link
+	Article  ReadabilityArticleWithMarkdown
+	Raw      TextResponse
+	Metadata Metadata
+}
+
+func (so SnapshotOptions) withDefaults() SnapshotOptions {
+	if !so.HTML && !so.Links && !so.Article && !so.RawText && !so.Metadata {
+		return SnapshotOptions{HTML: true, Links: true, Article: true, RawText: true, Metadata: true, LinkOptions: so.LinkOptions}
+	}
+	return so
+}
+
+// Snapshot 在同一个 worker 中只加载一次页面，同时返回渲染后 HTML、链接、正文、主文档原始响应和页面元数据
+func (c *Client) Snapshot(ctx context.Context, url string, so SnapshotOptions, opts ...RequestOption) (Snapshot, error) {
+	so = so.withDefaults()
+
+	var snapshot Snapshot
+	err := c.visitWithOptions(ctx, url, NewRequestOptions(opts...), true, func(page *rod.Page, response *proto.NetworkResponseReceived) error {
+		var rawBody string
+		var rawErr error
+		if so.RawText || so.Article {
+			rawBody, rawErr = readResponseBody(page, response)
+		}
+		if so.RawText {
+			if rawErr != nil {
+				return rawErr
+			}
+			snapshot.Raw = newTextResponse(rawBody, response)
+			// 响应体已由浏览器解码，与 RawText 一样按响应头和文档内容识别编码，只用于填充 Charset
+			_, snapshot.Raw.Charset = detectCharset([]byte(rawBody), snapshot.Raw.Header.Get("Content-Type"))
+		}
+
+		var err error
+		if so.HTML {
+			if snapshot.HTML, err = page.HTML(); err != nil {
+				return err
+			}
+		}
+		if so.Links {
+			raw, err := evalLinks(page)
+			if err != nil {
+				return err
+			}
+			snapshot.Links = formatLinks(raw.Links)
+			snapshot.LinkList = filterLinkList(buildLinkList(raw.PageURL, raw.Links), so.LinkOptions)
+		}
+		if so.Metadata {
+			if snapshot.Metadata, err = collectMetadata(page); err != nil {
+				return err
+			}
+		}
+		if so.Article {
+			if rawErr == nil {
+				snapshot.Article.RawHTML = rawBody
+			}
+			if err = fillReadabilityArticle(page, url, &snapshot.Article); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return snapshot, err
+}
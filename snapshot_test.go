@@ -0,0 +1,62 @@
+package pageviewer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshotOptionsDefaultsToAllResults(t *testing.T) {
+	assert.Equal(t, SnapshotOptions{HTML: true, Links: true, Article: true, RawText: true, Metadata: true}, SnapshotOptions{}.withDefaults())
+	assert.Equal(t, SnapshotOptions{Links: true}, SnapshotOptions{Links: true}.withDefaults())
+}
+
+func TestClientSnapshotLoadsPageOnce(t *testing.T) {
+	var documentRequests atomic.Int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		documentRequests.Add(1)
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("X-Test", "snapshot")
+		_, _ = w.Write([]byte(`<html><head><title>Snapshot</title></head><body><article><h1>Snapshot</h1><p>snapshot body text</p><a href="/next">next page</a></article></body></html>`))
+	}))
+	defer s.Close()
+
+	client := newTestClient(t, Config{PoolSize: 1, Warmup: 1})
+
+	snapshot, err := client.Snapshot(context.Background(), s.URL, SnapshotOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), documentRequests.Load())
+	assert.Contains(t, snapshot.HTML, "snapshot body text")
+	assert.Contains(t, snapshot.Links, s.URL+"/next")
+	assert.Contains(t, snapshot.Article.Content, "snapshot body text")
+	assert.Contains(t, snapshot.Article.RawHTML, "<h1>Snapshot</h1>")
+	assert.Contains(t, snapshot.Raw.Body, "<h1>Snapshot</h1>")
+	assert.Equal(t, http.StatusOK, snapshot.Raw.StatusCode)
+	assert.Equal(t, "snapshot", snapshot.Raw.Header.Get("X-Test"))
+	assert.Equal(t, "Snapshot", snapshot.Metadata.Title)
+}
+
+func TestClientSnapshotOnlyCollectsRequestedResults(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><body><a href="/next">next page</a></body></html>`))
+	}))
+	defer s.Close()
+
+	client := newTestClient(t, Config{PoolSize: 1, Warmup: 1})
+
+	snapshot, err := client.Snapshot(context.Background(), s.URL, SnapshotOptions{Links: true})
+	require.NoError(t, err)
+	assert.Contains(t, snapshot.Links, "next page")
+	assert.Empty(t, snapshot.HTML)
+	assert.Empty(t, snapshot.Raw.Body)
+	assert.Empty(t, snapshot.Article.Content)
+}
@@ -23,16 +23,21 @@ var (
 
 const testBrowserLockPortEnv = "PAGEVIEWER_TEST_BROWSER_LOCK_PORT"
 
+// testWaitStrategy 缩短各稳定等待阶段，newTestClient 在 Config 没有设置 WaitStrategy 时使用
+var testWaitStrategy = WaitStrategy{
+	Name:        "test",
+	Load:        750 * time.Millisecond,
+	Idle:        100 * time.Millisecond,
+	RequestIdle: 50 * time.Millisecond,
+	DOMStable:   100 * time.Millisecond,
+}
+
 func TestMain(m *testing.M) {
 	if err := configureTestBrowserLockPort(); err != nil {
 		panic(err)
 	}
 
 	DefaultWaitStableTimeout = 750 * time.Millisecond
-	browserWaitLoadTimeoutCap = 750 * time.Millisecond
-	browserWaitIdleTimeoutCap = 100 * time.Millisecond
-	browserWaitRequestIdleTimeoutCap = 50 * time.Millisecond
-	browserWaitDOMStableTimeoutCap = 100 * time.Millisecond
 	browserCloseWaitTimeout = 500 * time.Millisecond
 	browserKillWaitTimeout = 750 * time.Millisecond
 	browserProcessPollInterval = 25 * time.Millisecond
@@ -123,6 +128,9 @@ func newTestClient(t *testing.T, cfg Config) *Client {
 		traces:         newTraceRecorder(defaultTraceCapacity),
 		poolSize:       cfg.PoolSize,
 		acquireTimeout: cfg.AcquireTimeout,
+		waitStrategy:   cfg.WaitStrategy.or(testWaitStrategy),
+		workerReset:    cfg.WorkerReset,
+		recycler:       newWorkerRecycler(cfg),
 		repairWorkers:  true,
 		closeCh:        make(chan struct{}),
 	}
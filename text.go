@@ -2,74 +2,41 @@ package pageviewer
 
 import (
 	"context"
-	"errors"
 	"net/http"
 	"strings"
-	"time"
 
 	"github.com/go-rod/rod/lib/proto"
 )
 
 func (c *Client) RawText(ctx context.Context, url string, opts ...RequestOption) (resp TextResponse, err error) {
 	ro := NewRequestOptions(opts...)
-	trace := c.beginTrace(ro.TraceID, traceModeText, url)
-	defer func() {
-		trace.finish(err)
-	}()
+	err = c.runOnWorker(ctx, url, ro, traceModeText, func(req *workerRequest) (*proto.NetworkResponseReceived, error) {
+		po := c.pageOptions(ro)
+		po.blockSubresources = true
 
-	if err = c.beginTrackedOperation(); err != nil {
-		return TextResponse{}, err
-	}
-	defer c.inflight.Done()
-
-	if err = ctx.Err(); err != nil {
-		return TextResponse{}, err
-	}
-	if c.browser == nil || c.pool == nil {
-		return TextResponse{}, ErrBrowserUnavailable
-	}
-
-	acquireCtx, stopAcquire := c.acquireContext(ctx)
-	defer stopAcquire()
-
-	acquireStart := time.Now()
-	worker, release, err := c.pool.acquire(acquireCtx, c.acquireWorkerTimeout(ctx, ro))
-	trace.setAcquireWait(time.Since(acquireStart))
-	if err != nil {
-		if errors.Is(err, context.Canceled) && c.closed.Load() && ctx.Err() == nil {
-			err = ErrClosed
+		result, err := c.browser.navigateTextPage(ctx, req.page, url, po)
+		if err != nil {
+			req.state = workerStateBroken
+			return result.response, err
 		}
-		return TextResponse{}, err
-	}
-	trace.setWorkerID(worker.id)
 
-	state := workerStateReady
-	defer func() {
-		release(state)
-		if state == workerStateBroken {
-			trace.markBrokenWorker()
-			if c.repairWorkers {
-				c.scheduleRepair(worker)
-				return
-			}
-			c.retireWorker(worker)
+		resp = newTextResponse("", result.response)
+		if result.decoded {
+			// 浏览器已经解码，只识别原始编码的名称
+			_, resp.Charset = detectCharset(result.data, resp.Header.Get("Content-Type"))
+			resp.Body = string(result.data)
+		} else if resp.Body, resp.Charset, err = decodeCharset(result.data, resp.Header.Get("Content-Type")); err != nil {
+			return result.response, err
 		}
-	}()
-
-	po := ro.pageOptions()
-	po.blockSubresources = true
-
-	result, err := c.browser.navigateTextPage(ctx, worker.page, url, po)
-	trace.setResponse(result.response)
+		if ro.UndecodedBody {
+			resp.RawBody = result.data
+		}
+		return result.response, nil
+	})
 	if err != nil {
-		state = workerStateBroken
 		return TextResponse{}, err
 	}
-	if !isReusableWorkerPage(worker.page) {
-		state = workerStateBroken
-	}
-
-	return newTextResponse(result.body, result.response), nil
+	return resp, nil
 }
 
 func newTextResponse(body string, document *proto.NetworkResponseReceived) TextResponse {
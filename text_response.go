@@ -3,9 +3,11 @@ package pageviewer
 import "net/http"
 
 type TextResponse struct {
-	Body        string
+	Body        string // 已转成 UTF-8 的响应正文
 	ContentType string
 	StatusCode  int
 	FinalURL    string
 	Header      http.Header
+	Charset     string // 检测到的原始编码，例如 utf-8、gbk、big5、shift_jis
+	RawBody     []byte // 未解码的原始字节，仅在 WithUndecodedBody(true) 时返回；主文档未经过响应阶段拦截时为浏览器解码后的 UTF-8
 }
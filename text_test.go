@@ -5,6 +5,8 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -156,3 +158,18 @@ func TestClientRawText_ContextDeadlineCancelsBlockedNavigation(t *testing.T) {
 		t.Fatal("RawText did not return after context deadline")
 	}
 }
+
+func TestClientRawTextReadsFileURLWithoutResponseInterception(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "page.html")
+	require.NoError(t, os.WriteFile(path, []byte(`<html><head><meta charset="utf-8"></head><body>本地文件</body></html>`), 0o644))
+
+	client := newTestClient(t, Config{PoolSize: 1, Warmup: 1})
+
+	// file:// 的主文档不会在响应阶段暂停，使用生产环境默认的 20s 等待时长时也要立即读取响应体，不能等到超时
+	start := time.Now()
+	resp, err := client.RawText(context.Background(), "file://"+path, WithWaitTimeout(20*time.Second))
+	require.NoError(t, err)
+	assert.Less(t, time.Since(start), 5*time.Second)
+	assert.Contains(t, resp.Body, "本地文件")
+	assert.Equal(t, "utf-8", resp.Charset)
+}
@@ -14,6 +14,7 @@ const (
 	defaultTraceCapacity = 128
 	traceModeDOM         = "dom"
 	traceModeText        = "text"
+	traceModeBytes       = "bytes"
 )
 
 var (
@@ -22,18 +23,24 @@ var (
 )
 
 type TraceAttempt struct {
-	URL          string
-	Mode         string
-	WorkerID     int
-	StartedAt    time.Time
-	FinishedAt   time.Time
-	AcquireWait  time.Duration
-	StatusCode   int
-	ContentType  string
-	FinalURL     string
-	ErrorMessage string
-	BrokenWorker bool
-	sequence     uint64
+	URL           string
+	Mode          string
+	WorkerID      int
+	StartedAt     time.Time
+	FinishedAt    time.Time
+	AcquireWait   time.Duration
+	StatusCode    int
+	ContentType   string
+	FinalURL      string
+	ErrorMessage  string
+	BrokenWorker  bool
+	NetworkLog    *HAR           // 启用 WithNetworkLog 时记录的 HAR 网络日志
+	Actions       []ActionResult // WithActions 中每个动作的执行结果
+	ScrollSteps   int            // WithAutoScroll 实际滚动的次数
+	Dialogs       []DialogRecord // 请求期间自动处理的 JS 对话框
+	Popups        int            // 请求期间 worker 页面打开的新页面数，归还 worker 时会全部关闭
+	ResetDuration time.Duration  // 归还 worker 前重置页面状态的耗时
+	sequence      uint64
 }
 
 type Trace struct {
@@ -114,6 +121,48 @@ func (s *traceSession) setResponse(response *proto.NetworkResponseReceived) {
 	s.attempt.FinalURL = response.Response.URL
 }
 
+func (s *traceSession) setNetworkLog(har *HAR) {
+	if s == nil {
+		return
+	}
+	s.attempt.NetworkLog = har
+}
+
+func (s *traceSession) addAction(result ActionResult) {
+	if s == nil {
+		return
+	}
+	s.attempt.Actions = append(s.attempt.Actions, result)
+}
+
+func (s *traceSession) setScrollSteps(steps int) {
+	if s == nil {
+		return
+	}
+	s.attempt.ScrollSteps = steps
+}
+
+func (s *traceSession) setDialogs(dialogs []DialogRecord) {
+	if s == nil {
+		return
+	}
+	s.attempt.Dialogs = dialogs
+}
+
+func (s *traceSession) setPopups(popups int) {
+	if s == nil {
+		return
+	}
+	s.attempt.Popups = popups
+}
+
+func (s *traceSession) setResetDuration(d time.Duration) {
+	if s == nil {
+		return
+	}
+	s.attempt.ResetDuration = d
+}
+
 func (s *traceSession) markBrokenWorker() {
 	if s == nil {
 		return
@@ -121,6 +170,20 @@ func (s *traceSession) markBrokenWorker() {
 	s.attempt.BrokenWorker = true
 }
 
+// current 返回一份补齐结束时间和错误信息的当前 attempt 副本，不会写入 recorder
+func (s *traceSession) current(err error) (string, TraceAttempt) {
+	if s == nil {
+		return "", TraceAttempt{}
+	}
+
+	attempt := s.attempt
+	attempt.FinishedAt = time.Now()
+	if err != nil {
+		attempt.ErrorMessage = err.Error()
+	}
+	return s.traceID, attempt
+}
+
 func (s *traceSession) finish(err error) {
 	if s == nil || s.recorder == nil || s.traceID == "" {
 		return
@@ -0,0 +1,212 @@
+package pageviewer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod"
+)
+
+// WaitCondition 页面完成默认的稳定等待之后、抽取之前需要满足的条件，
+// 通过 WaitVisible 等构造函数创建，可以用 WaitAll / WaitAny 组合
+type WaitCondition struct {
+	name string
+	wait func(page *rod.Page) error
+}
+
+func (w WaitCondition) String() string {
+	return w.name
+}
+
+func (w WaitCondition) run(page *rod.Page) error {
+	if w.wait == nil {
+		return nil
+	}
+	return w.wait(page)
+}
+
+// WaitVisible 等待匹配 CSS 选择器的元素出现并可见
+func WaitVisible(selector string) WaitCondition {
+	return WaitCondition{
+		name: fmt.Sprintf("visible(%q)", selector),
+		wait: func(page *rod.Page) error {
+			el, err := page.Element(selector)
+			if err != nil {
+				return err
+			}
+			return el.WaitVisible()
+		},
+	}
+}
+
+// WaitGone 等待匹配 CSS 选择器的元素全部被移除或不可见，常用于等待骨架屏、loading 消失
+func WaitGone(selector string) WaitCondition {
+	return WaitCondition{
+		name: fmt.Sprintf("gone(%q)", selector),
+		wait: func(page *rod.Page) error {
+			return page.Wait(rod.Eval(`(selector) => Array.from(document.querySelectorAll(selector)).every((el) => {
+				const style = window.getComputedStyle(el);
+				const rect = el.getBoundingClientRect();
+				return style.display === 'none' || style.visibility === 'hidden' || rect.width === 0 || rect.height === 0;
+			})`, selector))
+		},
+	}
+}
+
+// WaitText 等待页面可见文本中出现 text
+func WaitText(text string) WaitCondition {
+	return WaitCondition{
+		name: fmt.Sprintf("text(%q)", text),
+		wait: func(page *rod.Page) error {
+			return page.Wait(rod.Eval(`(text) => !!document.body && document.body.innerText.includes(text)`, text))
+		},
+	}
+}
+
+// WaitJS 等待 JS 谓词返回 true，js 可以是表达式，例如 window.__APP_READY__ === true，也可以是无参函数
+func WaitJS(js string) WaitCondition {
+	return WaitCondition{
+		name: fmt.Sprintf("js(%q)", js),
+		wait: func(page *rod.Page) error {
+			return page.Wait(rod.Eval(jsFunction(js)))
+		},
+	}
+}
+
+// jsFunction 把表达式或无参函数统一包装成函数，rod.Eval 只接受函数，直接传入表达式会抛出 TypeError；
+// 每次调用都会重新求值表达式，结果是函数时再以相同的 this 和参数调用
+func jsFunction(js string) string {
+	js = strings.TrimRight(strings.TrimSpace(js), ";")
+	return "function() { const v = (" + js + "\n); return typeof v === 'function' ? v.apply(this, arguments) : v }"
+}
+
+// WaitNetworkIdle 等待网络空闲 idle 时长，excludes 为需要忽略的 URL 正则，例如长轮询或统计上报接口
+func WaitNetworkIdle(idle time.Duration, excludes ...string) WaitCondition {
+	return WaitCondition{
+		name: fmt.Sprintf("network-idle(%s)", idle),
+		wait: func(page *rod.Page) error {
+			page.WaitRequestIdle(idle, nil, excludes, nil)()
+			return page.GetContext().Err()
+		},
+	}
+}
+
+// WaitDelay 固定等待 d
+func WaitDelay(d time.Duration) WaitCondition {
+	return WaitCondition{
+		name: fmt.Sprintf("delay(%s)", d),
+		wait: func(page *rod.Page) error {
+			timer := time.NewTimer(d)
+			defer timer.Stop()
+			select {
+			case <-page.GetContext().Done():
+				return page.GetContext().Err()
+			case <-timer.C:
+				return nil
+			}
+		},
+	}
+}
+
+// WaitURL 等待页面地址匹配 JS 正则 pattern，适用于前端路由跳转或客户端重定向
+func WaitURL(pattern string) WaitCondition {
+	return WaitCondition{
+		name: fmt.Sprintf("url(%q)", pattern),
+		wait: func(page *rod.Page) error {
+			return page.Wait(rod.Eval(`(pattern) => new RegExp(pattern).test(location.href)`, pattern))
+		},
+	}
+}
+
+// WaitAll 按顺序等待全部条件满足
+func WaitAll(conditions ...WaitCondition) WaitCondition {
+	return WaitCondition{
+		name: waitGroupName("all", conditions),
+		wait: func(page *rod.Page) error {
+			for _, condition := range conditions {
+				if err := condition.run(page); err != nil {
+					return fmt.Errorf("%s: %w", condition, err)
+				}
+			}
+			return nil
+		},
+	}
+}
+
+// WaitAny 并发等待，任意一个条件满足即返回
+func WaitAny(conditions ...WaitCondition) WaitCondition {
+	return WaitCondition{
+		name: waitGroupName("any", conditions),
+		wait: func(page *rod.Page) error {
+			if len(conditions) == 0 {
+				return nil
+			}
+
+			ctx, cancel := context.WithCancel(page.GetContext())
+			defer cancel()
+
+			errCh := make(chan error, len(conditions))
+			for _, condition := range conditions {
+				go func() {
+					errCh <- condition.run(page.Context(ctx))
+				}()
+			}
+
+			var errs []error
+			for range conditions {
+				err := <-errCh
+				if err == nil {
+					return nil
+				}
+				errs = append(errs, err)
+			}
+			return errors.Join(errs...)
+		},
+	}
+}
+
+func waitGroupName(kind string, conditions []WaitCondition) string {
+	names := make([]string, 0, len(conditions))
+	for _, condition := range conditions {
+		names = append(names, condition.name)
+	}
+	return kind + "(" + strings.Join(names, ", ") + ")"
+}
+
+// minWaitConditionTimeout 稳定等待用完 WaitTimeout 之后，WithWaitFor 条件仍然至少可以等待的时长，
+// 保证从不触发 load 的页面（例如长轮询的统计请求）也会检查一次已经满足的条件
+const minWaitConditionTimeout = time.Second
+
+// waitForConditions 依次等待全部条件，总时长不超过 timeout（稳定等待之后剩余的时长），但至少为 minWaitConditionTimeout；
+// 超时返回 ErrWaitTimeout，调用方 ctx 取消时返回 ctx.Err()
+func waitForConditions(ctx context.Context, page *rod.Page, timeout time.Duration, conditions []WaitCondition) error {
+	if len(conditions) == 0 {
+		return nil
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	condition := WaitAll(conditions...)
+	if len(conditions) == 1 {
+		condition = conditions[0]
+	}
+	timeout = max(timeout, minWaitConditionTimeout)
+
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	err := condition.run(page.Context(waitCtx))
+	switch {
+	case err == nil:
+		return nil
+	case ctx.Err() != nil:
+		return ctx.Err()
+	case waitCtx.Err() != nil:
+		return fmt.Errorf("%w after %s: %s", ErrWaitTimeout, timeout, condition)
+	default:
+		return fmt.Errorf("pageviewer: wait for %s: %w", condition, err)
+	}
+}
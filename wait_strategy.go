@@ -0,0 +1,102 @@
+package pageviewer
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const defaultDOMStableDiff = 0.2
+
+// WaitStrategy 导航完成后默认稳定等待的各阶段上限，阶段依次为 WaitLoad、WaitIdle、网络请求空闲和 DOM 稳定，
+// 所有阶段的总时长仍受 WaitTimeout 限制。某个阶段的时长为 0 表示跳过该阶段；
+// 零值 WaitStrategy 表示沿用上一级配置（请求级沿用 Config.WaitStrategy，Config 沿用 WaitStrategyBalanced()）
+type WaitStrategy struct {
+	Name          string
+	Load          time.Duration // WaitLoad 上限
+	Idle          time.Duration // WaitIdle 上限
+	RequestIdle   time.Duration // 网络请求空闲上限
+	DOMStable     time.Duration // DOM 稳定上限
+	DOMStableDiff float64       // DOM 稳定判定允许的差异比例，<= 0 时使用 0.2；差异太小会一直等待
+}
+
+// WaitStrategyFast 只等待 load 事件和短暂的 DOM 稳定，适合对延迟敏感的服务端渲染页面
+func WaitStrategyFast() WaitStrategy {
+	return WaitStrategy{
+		Name:      "fast",
+		Load:      5 * time.Second,
+		DOMStable: 500 * time.Millisecond,
+	}
+}
+
+// WaitStrategyBalanced 默认策略
+func WaitStrategyBalanced() WaitStrategy {
+	return WaitStrategy{
+		Name:        "balanced",
+		Load:        15 * time.Second,
+		Idle:        5 * time.Second,
+		RequestIdle: 500 * time.Millisecond,
+		DOMStable:   2 * time.Second,
+	}
+}
+
+// WaitStrategyThorough 放宽各阶段上限，适合首屏之后还会持续加载数据的慢 SPA
+func WaitStrategyThorough() WaitStrategy {
+	return WaitStrategy{
+		Name:          "thorough",
+		Load:          30 * time.Second,
+		Idle:          10 * time.Second,
+		RequestIdle:   2 * time.Second,
+		DOMStable:     5 * time.Second,
+		DOMStableDiff: 0.05,
+	}
+}
+
+// WaitStrategyRawDocumentOnly 跳过全部阶段，主文档响应完成后立即抽取，适合不依赖脚本渲染的页面
+func WaitStrategyRawDocumentOnly() WaitStrategy {
+	return WaitStrategy{
+		Name: "raw-document-only",
+	}
+}
+
+// WaitStrategyByName 按名称返回预设策略，名称为 fast、balanced、thorough、raw-document-only
+func WaitStrategyByName(name string) (WaitStrategy, error) {
+	for _, preset := range []func() WaitStrategy{WaitStrategyFast, WaitStrategyBalanced, WaitStrategyThorough, WaitStrategyRawDocumentOnly} {
+		if strategy := preset(); strings.EqualFold(strategy.Name, strings.TrimSpace(name)) {
+			return strategy, nil
+		}
+	}
+	return WaitStrategy{}, fmt.Errorf("pageviewer: unknown wait strategy %q", name)
+}
+
+// IsZero 判断是否为未设置的零值
+func (s WaitStrategy) IsZero() bool {
+	return s == WaitStrategy{}
+}
+
+func (s WaitStrategy) String() string {
+	return s.Name
+}
+
+// or 当前策略为零值时返回 fallback
+func (s WaitStrategy) or(fallback WaitStrategy) WaitStrategy {
+	if s.IsZero() {
+		return fallback
+	}
+	return s
+}
+
+func (s WaitStrategy) domStableDiff() float64 {
+	if s.DOMStableDiff <= 0 {
+		return defaultDOMStableDiff
+	}
+	return s.DOMStableDiff
+}
+
+// phaseTimeout 返回阶段实际可用的等待时长，阶段被跳过或总时长已经用完时返回 false
+func phaseTimeout(limit, remaining time.Duration) (time.Duration, bool) {
+	if limit <= 0 || remaining <= 0 {
+		return 0, false
+	}
+	return min(limit, remaining), true
+}
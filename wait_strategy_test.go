@@ -0,0 +1,82 @@
+package pageviewer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWaitStrategyByNameReturnsPresets(t *testing.T) {
+	strategy, err := WaitStrategyByName("Thorough")
+	require.NoError(t, err)
+	assert.Equal(t, WaitStrategyThorough(), strategy)
+
+	strategy, err = WaitStrategyByName("raw-document-only")
+	require.NoError(t, err)
+	assert.False(t, strategy.IsZero())
+
+	_, err = WaitStrategyByName("slow")
+	assert.Error(t, err)
+}
+
+func TestWaitStrategyPresetsReturnCopies(t *testing.T) {
+	strategy := WaitStrategyBalanced()
+	strategy.Load = 0
+	strategy.Name = "changed"
+
+	assert.Equal(t, 15*time.Second, WaitStrategyBalanced().Load)
+	fromName, err := WaitStrategyByName("balanced")
+	require.NoError(t, err)
+	assert.Equal(t, WaitStrategyBalanced(), fromName)
+}
+
+func TestPhaseTimeoutSkipsDisabledAndExhaustedPhases(t *testing.T) {
+	timeout, ok := phaseTimeout(2*time.Second, time.Second)
+	assert.True(t, ok)
+	assert.Equal(t, time.Second, timeout)
+
+	timeout, ok = phaseTimeout(500*time.Millisecond, time.Second)
+	assert.True(t, ok)
+	assert.Equal(t, 500*time.Millisecond, timeout)
+
+	_, ok = phaseTimeout(0, time.Second)
+	assert.False(t, ok)
+	_, ok = phaseTimeout(time.Second, -time.Millisecond)
+	assert.False(t, ok)
+}
+
+func TestClientPageOptionsFallsBackToConfigWaitStrategy(t *testing.T) {
+	client := &Client{waitStrategy: WaitStrategyFast()}
+
+	po := client.pageOptions(NewRequestOptions())
+	assert.Equal(t, WaitStrategyFast(), po.waitStrategy)
+
+	po = client.pageOptions(NewRequestOptions(WithWaitStrategy(WaitStrategyRawDocumentOnly())))
+	assert.Equal(t, WaitStrategyRawDocumentOnly(), po.waitStrategy)
+
+	assert.Equal(t, defaultDOMStableDiff, WaitStrategyBalanced().domStableDiff())
+	assert.Equal(t, 0.05, WaitStrategyThorough().domStableDiff())
+}
+
+func TestClientWaitStrategySkipsPhases(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(`<html><head><title>strategy</title></head><body><p>server rendered</p></body></html>`))
+	}))
+	defer s.Close()
+
+	client := newTestClient(t, Config{PoolSize: 1, Warmup: 1})
+
+	html, err := client.HTML(context.Background(), s.URL, WithWaitStrategy(WaitStrategyRawDocumentOnly()))
+	require.NoError(t, err)
+	assert.Contains(t, html, "server rendered")
+
+	html, err = client.HTML(context.Background(), s.URL, WithWaitStrategy(WaitStrategy{Name: "load-only", Load: time.Second}))
+	require.NoError(t, err)
+	assert.Contains(t, html, "server rendered")
+}
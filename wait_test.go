@@ -0,0 +1,86 @@
+package pageviewer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWaitConditionNames(t *testing.T) {
+	condition := WaitAny(
+		WaitAll(WaitVisible("#app"), WaitGone(".skeleton")),
+		WaitText("ready"),
+		WaitURL("/done$"),
+		WaitNetworkIdle(500*time.Millisecond),
+		WaitDelay(time.Second),
+	)
+	assert.Equal(t, `any(all(visible("#app"), gone(".skeleton")), text("ready"), url("/done$"), network-idle(500ms), delay(1s))`, condition.String())
+	assert.NoError(t, WaitCondition{}.run(nil))
+}
+
+func TestJSFunction(t *testing.T) {
+	assert.Equal(t, "function() { const v = (window.__APP_READY__ === true\n); return typeof v === 'function' ? v.apply(this, arguments) : v }", jsFunction(" window.__APP_READY__ === true; "))
+	assert.Equal(t, "function() { const v = (() => 1\n); return typeof v === 'function' ? v.apply(this, arguments) : v }", jsFunction("() => 1"))
+}
+
+func TestClientWaitForConditionsCheckedAfterBudgetExhausted(t *testing.T) {
+	release := make(chan struct{})
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/poll.js" {
+			// 长轮询请求一直不返回，页面不会触发 load
+			select {
+			case <-release:
+			case <-r.Context().Done():
+			}
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(`<html><head><title>poll</title></head><body><main id="app">ready content</main><script src="/poll.js"></script></body></html>`))
+	}))
+	defer s.Close()
+	defer close(release)
+
+	client := newTestClient(t, Config{PoolSize: 1, Warmup: 1})
+
+	html, err := client.HTML(context.Background(), s.URL, WithWaitTimeout(2*time.Second), WithWaitStrategy(WaitStrategyBalanced()), WithWaitFor(WaitVisible("#app")))
+	require.NoError(t, err)
+	assert.Contains(t, html, "ready content")
+
+	_, err = client.HTML(context.Background(), s.URL, WithWaitTimeout(2*time.Second), WithWaitStrategy(WaitStrategyBalanced()), WithWaitFor(WaitText("never")))
+	assert.ErrorIs(t, err, ErrWaitTimeout)
+}
+
+func TestClientWaitForConditions(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(`<html><head><title>spa</title></head><body><div class="skeleton">loading</div><script>
+setTimeout(() => {
+	document.querySelector('.skeleton').remove();
+	const el = document.createElement('main');
+	el.id = 'app';
+	el.textContent = 'late content';
+	document.body.appendChild(el);
+	window.__APP_READY__ = true;
+}, 1500);
+</script></body></html>`))
+	}))
+	defer s.Close()
+
+	client := newTestClient(t, Config{PoolSize: 1, Warmup: 1})
+
+	html, err := client.HTML(context.Background(), s.URL, WithWaitTimeout(5*time.Second), WithWaitFor(WaitVisible("#app"), WaitGone(".skeleton")))
+	require.NoError(t, err)
+	assert.Contains(t, html, "late content")
+
+	html, err = client.HTML(context.Background(), s.URL, WithWaitTimeout(5*time.Second), WithWaitFor(WaitAny(WaitText("never"), WaitJS("window.__APP_READY__ === true"))))
+	require.NoError(t, err)
+	assert.Contains(t, html, "late content")
+
+	_, err = client.HTML(context.Background(), s.URL, WithWaitTimeout(2*time.Second), WithWaitFor(WaitText("never")))
+	assert.ErrorIs(t, err, ErrWaitTimeout)
+}
@@ -0,0 +1,349 @@
+package pageviewer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base32"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod/lib/proto"
+)
+
+const (
+	warcVersion              = "WARC/1.1"
+	warcContentTypeRequest   = "application/http;msgtype=request"
+	warcContentTypeResponse  = "application/http;msgtype=response"
+	warcContentTypeWARCField = "application/warc-fields"
+)
+
+const (
+	WARCTypeWarcinfo = "warcinfo"
+	WARCTypeRequest  = "request"
+	WARCTypeResponse = "response"
+	WARCTypeMetadata = "metadata"
+)
+
+// WARCRecord 一条 WARC 记录，RecordID 和 Date 为空时自动生成
+type WARCRecord struct {
+	Type          string
+	RecordID      string
+	Date          time.Time
+	TargetURI     string
+	ContentType   string
+	ConcurrentTo  string
+	IPAddress     string
+	PayloadDigest string
+	Block         []byte
+}
+
+// WARCWriter 按 WARC 1.1 规范写入记录，开启压缩时每条记录单独作为一个 gzip member；
+// 写入同一个 io.Writer 的 WARCWriter 共享写锁，每次 WriteRecords 的记录批次不会互相穿插
+type WARCWriter struct {
+	w        io.Writer
+	compress bool
+}
+
+// warcWriteLocks 按底层 io.Writer 登记的写锁，没有写入者时移除，避免长期持有调用方的 io.Writer
+var warcWriteLocks = struct {
+	sync.Mutex
+	m map[io.Writer]*warcWriteLock
+}{m: make(map[io.Writer]*warcWriteLock)}
+
+type warcWriteLock struct {
+	sync.Mutex
+	refs int
+}
+
+// lockWARCWriter 获取 w 的写锁，返回解锁函数；不可比较的 io.Writer 无法登记，退化为共享同一把锁
+func lockWARCWriter(w io.Writer) func() {
+	key := w
+	if !reflect.TypeOf(w).Comparable() {
+		key = nil
+	}
+
+	warcWriteLocks.Lock()
+	l := warcWriteLocks.m[key]
+	if l == nil {
+		l = &warcWriteLock{}
+		warcWriteLocks.m[key] = l
+	}
+	l.refs++
+	warcWriteLocks.Unlock()
+
+	l.Lock()
+	return func() {
+		l.Unlock()
+		warcWriteLocks.Lock()
+		if l.refs--; l.refs == 0 {
+			delete(warcWriteLocks.m, key)
+		}
+		warcWriteLocks.Unlock()
+	}
+}
+
+// NewWARCWriter 创建 WARC 写入器
+func NewWARCWriter(w io.Writer, compress bool) *WARCWriter {
+	return &WARCWriter{w: w, compress: compress}
+}
+
+// WriteWarcinfo 写入描述整个 WARC 文件的 warcinfo 记录
+func (ww *WARCWriter) WriteWarcinfo(filename string) error {
+	fields := warcFields{
+		{"software", "github.com/LubyRuffy/pageviewer"},
+		{"format", "WARC File Format 1.1"},
+		{"conformsTo", "https://iipc.github.io/warc-specifications/specifications/warc-format/warc-1.1/"},
+	}
+	if filename != "" {
+		fields = append(fields, [2]string{"filename", filename})
+	}
+	return ww.WriteRecords(WARCRecord{
+		Type:        WARCTypeWarcinfo,
+		ContentType: warcContentTypeWARCField,
+		Block:       fields.bytes(),
+	})
+}
+
+// WriteRecords 把多条记录编码后一次性写入底层 io.Writer，保证同一批记录连续
+func (ww *WARCWriter) WriteRecords(records ...WARCRecord) error {
+	if ww == nil || ww.w == nil || len(records) == 0 {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	for i := range records {
+		if err := ww.encode(&buf, &records[i]); err != nil {
+			return err
+		}
+	}
+
+	unlock := lockWARCWriter(ww.w)
+	defer unlock()
+
+	_, err := ww.w.Write(buf.Bytes())
+	return err
+}
+
+func (ww *WARCWriter) encode(dst *bytes.Buffer, record *WARCRecord) error {
+	if record.RecordID == "" {
+		record.RecordID = newWARCRecordID()
+	}
+	if record.Date.IsZero() {
+		record.Date = time.Now()
+	}
+
+	var raw bytes.Buffer
+	raw.WriteString(warcVersion + "\r\n")
+	writeWARCHeader(&raw, "WARC-Type", record.Type)
+	writeWARCHeader(&raw, "WARC-Record-ID", record.RecordID)
+	writeWARCHeader(&raw, "WARC-Date", record.Date.UTC().Format("2006-01-02T15:04:05.000000Z"))
+	writeWARCHeader(&raw, "WARC-Target-URI", record.TargetURI)
+	writeWARCHeader(&raw, "WARC-Concurrent-To", record.ConcurrentTo)
+	writeWARCHeader(&raw, "WARC-IP-Address", record.IPAddress)
+	writeWARCHeader(&raw, "WARC-Block-Digest", warcDigest(record.Block))
+	writeWARCHeader(&raw, "WARC-Payload-Digest", record.PayloadDigest)
+	writeWARCHeader(&raw, "Content-Type", record.ContentType)
+	writeWARCHeader(&raw, "Content-Length", strconv.Itoa(len(record.Block)))
+	raw.WriteString("\r\n")
+	raw.Write(record.Block)
+	raw.WriteString("\r\n\r\n")
+
+	if !ww.compress {
+		_, err := dst.Write(raw.Bytes())
+		return err
+	}
+
+	gz := gzip.NewWriter(dst)
+	if _, err := gz.Write(raw.Bytes()); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// writeCapture 写入一次请求抓到的请求 / 响应记录，以及携带 trace 字段的 metadata 记录
+func (ww *WARCWriter) writeCapture(entries []*networkEntry, traceID string, attempt TraceAttempt) error {
+	if ww == nil {
+		return nil
+	}
+
+	records := make([]WARCRecord, 0, len(entries)*2+1)
+	var mainResponseID string
+	for _, entry := range entries {
+		if entry.Request == nil || entry.Response == nil || !isHTTPURL(entry.Request.URL) {
+			continue
+		}
+
+		date := entry.StartedAt
+		response := WARCRecord{
+			Type:          WARCTypeResponse,
+			RecordID:      newWARCRecordID(),
+			Date:          date,
+			TargetURI:     entry.Request.URL,
+			ContentType:   warcContentTypeResponse,
+			IPAddress:     entry.Response.RemoteIPAddress,
+			PayloadDigest: warcDigest(entry.Body),
+			Block:         warcHTTPResponseBlock(entry.Response, entry.Body),
+		}
+		request := WARCRecord{
+			Type:         WARCTypeRequest,
+			Date:         date,
+			TargetURI:    entry.Request.URL,
+			ContentType:  warcContentTypeRequest,
+			ConcurrentTo: response.RecordID,
+			Block:        warcHTTPRequestBlock(entry.Request),
+		}
+		records = append(records, request, response)
+
+		if entry.ResourceType == proto.NetworkResourceTypeDocument &&
+			(mainResponseID == "" || entry.Request.URL == attempt.FinalURL) {
+			mainResponseID = response.RecordID
+		}
+	}
+
+	records = append(records, WARCRecord{
+		Type:         WARCTypeMetadata,
+		Date:         attempt.StartedAt,
+		TargetURI:    attempt.URL,
+		ContentType:  warcContentTypeWARCField,
+		ConcurrentTo: mainResponseID,
+		Block:        warcTraceFields(traceID, attempt).bytes(),
+	})
+
+	return ww.WriteRecords(records...)
+}
+
+type warcFields [][2]string
+
+func (f warcFields) bytes() []byte {
+	var buf bytes.Buffer
+	for _, field := range f {
+		buf.WriteString(field[0])
+		buf.WriteString(": ")
+		buf.WriteString(strings.NewReplacer("\r", " ", "\n", " ").Replace(field[1]))
+		buf.WriteString("\r\n")
+	}
+	return buf.Bytes()
+}
+
+func warcTraceFields(traceID string, attempt TraceAttempt) warcFields {
+	fields := warcFields{
+		{"trace-id", traceID},
+		{"mode", attempt.Mode},
+		{"url", attempt.URL},
+		{"worker-id", strconv.Itoa(attempt.WorkerID)},
+		{"started-at", attempt.StartedAt.UTC().Format(time.RFC3339Nano)},
+		{"finished-at", attempt.FinishedAt.UTC().Format(time.RFC3339Nano)},
+		{"acquire-wait", attempt.AcquireWait.String()},
+		{"status-code", strconv.Itoa(attempt.StatusCode)},
+		{"content-type", attempt.ContentType},
+		{"final-url", attempt.FinalURL},
+		{"broken-worker", strconv.FormatBool(attempt.BrokenWorker)},
+	}
+	if attempt.ErrorMessage != "" {
+		fields = append(fields, [2]string{"error", attempt.ErrorMessage})
+	}
+	return fields
+}
+
+func warcHTTPRequestBlock(request *proto.NetworkRequest) []byte {
+	target := request.URL
+	host := ""
+	if parsed, err := url.Parse(request.URL); err == nil {
+		target = parsed.RequestURI()
+		host = parsed.Host
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%s %s HTTP/1.1\r\n", request.Method, target)
+	header := warcHeader(request.Headers)
+	if header.Get("Host") == "" && host != "" {
+		header.Set("Host", host)
+	}
+	writeWARCHTTPHeader(&buf, header)
+	buf.WriteString("\r\n")
+	buf.WriteString(request.PostData)
+	return buf.Bytes()
+}
+
+// warcHTTPResponseBlock 浏览器返回的响应体已经解压，因此去掉 Content-Encoding / Transfer-Encoding 并按实际长度重写 Content-Length
+func warcHTTPResponseBlock(response *proto.NetworkResponse, body []byte) []byte {
+	statusText := response.StatusText
+	if statusText == "" {
+		statusText = http.StatusText(response.Status)
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "HTTP/1.1 %d %s\r\n", response.Status, statusText)
+	header := warcHeader(response.Headers)
+	header.Del("Content-Encoding")
+	header.Del("Transfer-Encoding")
+	header.Set("Content-Length", strconv.Itoa(len(body)))
+	writeWARCHTTPHeader(&buf, header)
+	buf.WriteString("\r\n")
+	buf.Write(body)
+	return buf.Bytes()
+}
+
+// warcHeader 把 CDP 头转换为 http.Header，多值头在 CDP 中以换行分隔，HTTP/2 伪头会被丢弃
+func warcHeader(headers proto.NetworkHeaders) http.Header {
+	result := make(http.Header, len(headers))
+	for key, value := range headers {
+		if strings.HasPrefix(key, ":") {
+			continue
+		}
+		for _, v := range strings.Split(value.Str(), "\n") {
+			result.Add(key, strings.TrimSpace(v))
+		}
+	}
+	return result
+}
+
+func writeWARCHTTPHeader(buf *bytes.Buffer, header http.Header) {
+	keys := make([]string, 0, len(header))
+	for key := range header {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		for _, value := range header[key] {
+			fmt.Fprintf(buf, "%s: %s\r\n", key, value)
+		}
+	}
+}
+
+func writeWARCHeader(buf *bytes.Buffer, name, value string) {
+	if value == "" {
+		return
+	}
+	buf.WriteString(name)
+	buf.WriteString(": ")
+	buf.WriteString(value)
+	buf.WriteString("\r\n")
+}
+
+func warcDigest(data []byte) string {
+	sum := sha1.Sum(data)
+	return "sha1:" + base32.StdEncoding.EncodeToString(sum[:])
+}
+
+func newWARCRecordID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("<urn:uuid:%x-%x-%x-%x-%x>", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+func isHTTPURL(raw string) bool {
+	return strings.HasPrefix(raw, "http://") || strings.HasPrefix(raw, "https://")
+}
@@ -0,0 +1,245 @@
+package pageviewer
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ysmood/gson"
+)
+
+func readWARCMembers(t *testing.T, data []byte) []string {
+	t.Helper()
+
+	var members []string
+	reader := bufio.NewReader(bytes.NewReader(data))
+	for {
+		if _, err := reader.Peek(1); err == io.EOF {
+			return members
+		}
+		gz, err := gzip.NewReader(reader)
+		require.NoError(t, err)
+		gz.Multistream(false)
+		member, err := io.ReadAll(gz)
+		require.NoError(t, err)
+		members = append(members, string(member))
+	}
+}
+
+func TestWARCWriterWritesEachRecordAsGzipMember(t *testing.T) {
+	var buf bytes.Buffer
+	writer := NewWARCWriter(&buf, true)
+
+	require.NoError(t, writer.WriteWarcinfo("test.warc.gz"))
+	require.NoError(t, writer.WriteRecords(
+		WARCRecord{Type: WARCTypeMetadata, TargetURI: "https://example.com/", ContentType: "application/warc-fields", Block: []byte("a: b\r\n")},
+		WARCRecord{Type: WARCTypeMetadata, TargetURI: "https://example.com/", ContentType: "application/warc-fields", Block: []byte("c: d\r\n")},
+	))
+
+	members := readWARCMembers(t, buf.Bytes())
+	require.Len(t, members, 3)
+	assert.True(t, strings.HasPrefix(members[0], "WARC/1.1\r\nWARC-Type: warcinfo\r\n"))
+	assert.Contains(t, members[0], "filename: test.warc.gz\r\n")
+	assert.Contains(t, members[1], "WARC-Target-URI: https://example.com/\r\n")
+	assert.Contains(t, members[1], "Content-Length: 6\r\n")
+	assert.True(t, strings.HasSuffix(members[2], "c: d\r\n\r\n\r\n"))
+}
+
+func TestWARCWriterSharedAcrossGoroutinesKeepsBatchesContiguous(t *testing.T) {
+	var buf bytes.Buffer
+	writer := NewWARCWriter(&buf, true)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			block := []byte(fmt.Sprintf("batch: %d\r\n", i))
+			assert.NoError(t, writer.WriteRecords(
+				WARCRecord{Type: WARCTypeMetadata, ContentType: "application/warc-fields", Block: block},
+				WARCRecord{Type: WARCTypeMetadata, ContentType: "application/warc-fields", Block: block},
+			))
+		}(i)
+	}
+	wg.Wait()
+
+	members := readWARCMembers(t, buf.Bytes())
+	require.Len(t, members, 16)
+	for i := 0; i < len(members); i += 2 {
+		assert.Equal(t, members[i][strings.Index(members[i], "batch: "):], members[i+1][strings.Index(members[i+1], "batch: "):])
+	}
+}
+
+func TestWARCWritersOnSameWriterKeepBatchesContiguous(t *testing.T) {
+	var buf bytes.Buffer
+
+	// 每个请求的 WithWARC 都会各自创建 WARCWriter，写入同一个 io.Writer 时仍然按批次互斥
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			block := []byte(fmt.Sprintf("batch: %d\r\n", i))
+			assert.NoError(t, NewRequestOptions(WithWARC(&buf)).WARC.WriteRecords(
+				WARCRecord{Type: WARCTypeMetadata, ContentType: "application/warc-fields", Block: block},
+				WARCRecord{Type: WARCTypeMetadata, ContentType: "application/warc-fields", Block: block},
+			))
+		}(i)
+	}
+	wg.Wait()
+
+	members := readWARCMembers(t, buf.Bytes())
+	require.Len(t, members, 16)
+	for i := 0; i < len(members); i += 2 {
+		assert.Equal(t, members[i][strings.Index(members[i], "batch: "):], members[i+1][strings.Index(members[i+1], "batch: "):])
+	}
+	assert.Empty(t, warcWriteLocks.m)
+	assert.Nil(t, NewRequestOptions(WithWARC(nil)).WARC)
+}
+
+func TestWARCWriterWriteCaptureBuildsRequestResponseAndMetadata(t *testing.T) {
+	var buf bytes.Buffer
+	writer := NewWARCWriter(&buf, false)
+
+	entries := []*networkEntry{
+		{
+			ResourceType: proto.NetworkResourceTypeDocument,
+			Request: &proto.NetworkRequest{
+				URL:     "https://example.com/path?q=1",
+				Method:  "GET",
+				Headers: proto.NetworkHeaders{"Accept": gson.New("text/html")},
+			},
+			Response: &proto.NetworkResponse{
+				URL:    "https://example.com/path?q=1",
+				Status: 200,
+				Headers: proto.NetworkHeaders{
+					"Content-Type":     gson.New("text/html"),
+					"Content-Encoding": gson.New("gzip"),
+					"Set-Cookie":       gson.New("a=1\nb=2"),
+				},
+			},
+			StartedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+			Body:      []byte("<html>ok</html>"),
+		},
+		{
+			Request: &proto.NetworkRequest{URL: "data:text/plain,skip", Method: "GET"},
+			Response: &proto.NetworkResponse{
+				Status: 200,
+			},
+		},
+		{
+			Request: &proto.NetworkRequest{URL: "https://example.com/failed.js", Method: "GET"},
+		},
+	}
+
+	attempt := TraceAttempt{URL: "https://example.com/path?q=1", Mode: traceModeDOM, WorkerID: 3, StatusCode: 200, FinalURL: "https://example.com/path?q=1"}
+	require.NoError(t, writer.writeCapture(entries, "trace-warc", attempt))
+
+	out := buf.String()
+	assert.Equal(t, 3, strings.Count(out, "WARC/1.1\r\n"))
+	assert.Contains(t, out, "WARC-Type: request\r\n")
+	assert.Contains(t, out, "GET /path?q=1 HTTP/1.1\r\nAccept: text/html\r\nHost: example.com\r\n\r\n")
+	assert.Contains(t, out, "WARC-Type: response\r\n")
+	assert.Contains(t, out, "WARC-Date: 2024-01-02T03:04:05.000000Z\r\n")
+	assert.Contains(t, out, "HTTP/1.1 200 OK\r\nContent-Length: 15\r\nContent-Type: text/html\r\nSet-Cookie: a=1\r\nSet-Cookie: b=2\r\n\r\n<html>ok</html>")
+	assert.Contains(t, out, "WARC-Payload-Digest: "+warcDigest([]byte("<html>ok</html>")))
+	assert.NotContains(t, out, "Content-Encoding")
+	assert.NotContains(t, out, "data:text/plain")
+	assert.NotContains(t, out, "failed.js")
+	assert.Contains(t, out, "WARC-Type: metadata\r\n")
+	assert.Contains(t, out, "trace-id: trace-warc\r\n")
+	assert.Contains(t, out, "worker-id: 3\r\n")
+
+	responseID := out[strings.Index(out, "WARC-Type: response\r\nWARC-Record-ID: ")+len("WARC-Type: response\r\nWARC-Record-ID: "):]
+	responseID = responseID[:strings.Index(responseID, "\r\n")]
+	assert.Equal(t, 2, strings.Count(out, "WARC-Concurrent-To: "+responseID+"\r\n"))
+}
+
+func TestWARCDigestUsesBase32SHA1(t *testing.T) {
+	assert.Equal(t, "sha1:3I42H3S6NNFQ2MSVX7XZKYAYSCX5QBYJ", warcDigest(nil))
+}
+
+func TestClientHTMLWritesWARC(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/app.js":
+			w.Header().Set("Content-Type", "application/javascript")
+			_, _ = w.Write([]byte(`document.title = "warc";`))
+		default:
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			_, _ = w.Write([]byte(`<html><body><p>warc body</p><script src="/app.js"></script></body></html>`))
+		}
+	}))
+	defer s.Close()
+
+	client := newTestClient(t, Config{PoolSize: 1, Warmup: 1})
+
+	var buf bytes.Buffer
+	_, err := client.HTML(context.Background(), s.URL, WithWARC(&buf), WithTraceID("trace-warc-html"))
+	require.NoError(t, err)
+
+	out := strings.Join(readWARCMembers(t, buf.Bytes()), "")
+	assert.Contains(t, out, "WARC-Target-URI: "+s.URL+"/\r\n")
+	assert.Contains(t, out, "<p>warc body</p>")
+	assert.Contains(t, out, "WARC-Target-URI: "+s.URL+"/app.js\r\n")
+	assert.Contains(t, out, `document.title = "warc";`)
+	assert.Contains(t, out, "trace-id: trace-warc-html\r\n")
+}
+
+func TestNetworkCaptureMergesAbortedDocument(t *testing.T) {
+	request := &proto.NetworkRequest{URL: "https://example.com/report.pdf", Method: "GET"}
+	entry := &networkEntry{RequestID: "doc", ResourceType: proto.NetworkResourceTypeDocument, Request: request, ErrorText: "net::ERR_ABORTED", Canceled: true}
+	capture := &networkCapture{
+		entries:  []*networkEntry{entry},
+		byID:     map[proto.NetworkRequestID]*networkEntry{"doc": entry},
+		withBody: true,
+		stop:     func() {},
+	}
+
+	capture.setDocument(&proto.NetworkResponseReceived{
+		RequestID: "doc",
+		Response:  &proto.NetworkResponse{URL: request.URL, Status: 200, MIMEType: "application/pdf"},
+	}, []byte("%PDF-1.4"))
+	entries := capture.finish()
+
+	require.Len(t, entries, 1)
+	require.NotNil(t, entries[0].Response)
+	assert.Equal(t, 200, entries[0].Response.Status)
+	assert.Equal(t, []byte("%PDF-1.4"), entries[0].Body)
+	assert.Empty(t, entries[0].ErrorText)
+	assert.False(t, entries[0].Canceled)
+}
+
+func TestClientRawBytesWritesDocumentToWARC(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/pdf")
+		_, _ = w.Write([]byte("%PDF-1.4 warc payload"))
+	}))
+	defer s.Close()
+
+	client := newTestClient(t, Config{PoolSize: 1, Warmup: 1})
+
+	var buf bytes.Buffer
+	resp, err := client.RawBytes(context.Background(), s.URL, WithWARC(&buf), WithTraceID("trace-warc-bytes"))
+	require.NoError(t, err)
+	assert.Equal(t, "%PDF-1.4 warc payload", string(resp.Body))
+
+	out := strings.Join(readWARCMembers(t, buf.Bytes()), "")
+	assert.Contains(t, out, "WARC-Type: response\r\n")
+	assert.Contains(t, out, "WARC-Target-URI: "+s.URL+"/\r\n")
+	assert.Contains(t, out, "Content-Type: application/pdf\r\n")
+	assert.Contains(t, out, "%PDF-1.4 warc payload")
+	assert.Contains(t, out, "trace-id: trace-warc-bytes\r\n")
+}